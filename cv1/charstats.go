@@ -0,0 +1,105 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CharStats jsou statistiky znaků textu. Bigramy a trigramy se počítají přes
+// celý text včetně mezer, takže nejčastější bigram je právě pár, který
+// ByteTokenizer sloučí jako první.
+type CharStats struct {
+	Chars int `json:"chars"`
+	// Categories jsou počty znaků podle tříd (letter, digit, punct, space, other).
+	Categories map[string]int `json:"categories"`
+	// DiacriticsShare je podíl písmen s diakritikou (latinka mimo ASCII) mezi písmeny.
+	DiacriticsShare float64     `json:"diacritics_share"`
+	Top             []WordCount `json:"top"`
+	Bigrams         []WordCount `json:"bigrams"`
+	Trigrams        []WordCount `json:"trigrams"`
+	// WordLengths je histogram délek slov ve znacích (index = délka).
+	WordLengths   []int   `json:"word_lengths"`
+	AvgWordLength float64 `json:"avg_word_length"`
+}
+
+func (c CharClass) String() string {
+	switch c {
+	case Letter:
+		return "letter"
+	case Digit:
+		return "digit"
+	case Punct:
+		return "punct"
+	case Space:
+		return "space"
+	}
+	return "other"
+}
+
+// hasDiacritic hlásí písmeno latinky mimo ASCII, v češtině á, č, ď, é, ě, í,
+// ň, ó, ř, š, ť, ú, ů, ý, ž a jejich velké varianty.
+func hasDiacritic(r rune) bool {
+	return r > unicode.MaxASCII && unicode.Is(unicode.Latin, r)
+}
+
+// computeCharStats spočítá statistiky znaků a n-gramů s nejvýše top
+// položkami v tabulkách (top < 0 = všechny).
+func computeCharStats(text string, words []string, top int) CharStats {
+	s := CharStats{Categories: make(map[string]int)}
+	chars := make(map[string]int)
+	bigrams := make(map[string]int)
+	trigrams := make(map[string]int)
+
+	var letters, diacritics int
+	var prev1, prev2 rune = -1, -1
+	for _, r := range text {
+		s.Chars++
+		class := classOf(r)
+		s.Categories[class.String()]++
+		if class == Letter {
+			letters++
+			if hasDiacritic(r) {
+				diacritics++
+			}
+		}
+		chars[string(r)]++
+		if prev1 >= 0 {
+			bigrams[string([]rune{prev1, r})]++
+			if prev2 >= 0 {
+				trigrams[string([]rune{prev2, prev1, r})]++
+			}
+		}
+		prev2, prev1 = prev1, r
+	}
+	if letters > 0 {
+		s.DiacriticsShare = float64(diacritics) / float64(letters)
+	}
+	s.Top = topCounts(chars, top)
+	s.Bigrams = topCounts(bigrams, top)
+	s.Trigrams = topCounts(trigrams, top)
+
+	var totalLen int
+	for _, w := range words {
+		l := utf8.RuneCountInString(w)
+		for len(s.WordLengths) <= l {
+			s.WordLengths = append(s.WordLengths, 0)
+		}
+		s.WordLengths[l]++
+		totalLen += l
+	}
+	if len(words) > 0 {
+		s.AvgWordLength = float64(totalLen) / float64(len(words))
+	}
+	return s
+}
+
+// formatCounts zformátuje tabulku četností na jeden řádek výpisu.
+func formatCounts(pairs []WordCount) string {
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = strings.ReplaceAll(p.Word, " ", "␣") + " " + strconv.Itoa(p.Count)
+	}
+	return strings.Join(parts, ", ")
+}
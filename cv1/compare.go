@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// VocabComparison je srovnání slovníků dvou naučených tokenizerů. Tokeny se
+// porovnávají bez značek hranic slov a okrajových mezer, takže "ka<end_of_word>",
+// "▁ka" i " ka" jsou týž token; jejich četnosti se sečtou.
+type VocabComparison struct {
+	NameA, NameB string
+	SizeA, SizeB int // velikosti normalizovaných slovníků
+	Shared       int
+	Jaccard      float64
+	OnlyA        []VocabEntry // tokeny jen v A, sestupně podle četnosti
+	OnlyB        []VocabEntry
+	// LengthsA a LengthsB jsou histogramy délek tokenů ve znacích (index = délka).
+	LengthsA, LengthsB []int
+	// Disagreements jsou společné tokeny s největším rozdílem četností.
+	Disagreements []TokenDisagreement
+}
+
+// TokenDisagreement je společný token s četnostmi v obou slovnících.
+type TokenDisagreement struct {
+	Token        string
+	FreqA, FreqB int
+}
+
+// CompareVocabs porovná slovníky dvou výsledků učení.
+func CompareVocabs(nameA string, a *Result, nameB string, b *Result) VocabComparison {
+	va, vb := normalizedVocab(a), normalizedVocab(b)
+	c := VocabComparison{NameA: nameA, NameB: nameB, SizeA: len(va), SizeB: len(vb)}
+
+	for tok, fa := range va {
+		if fb, ok := vb[tok]; ok {
+			c.Shared++
+			c.Disagreements = append(c.Disagreements, TokenDisagreement{tok, fa, fb})
+		} else {
+			c.OnlyA = append(c.OnlyA, VocabEntry{ID: -1, Token: tok, Freq: fa})
+		}
+	}
+	for tok, fb := range vb {
+		if _, ok := va[tok]; !ok {
+			c.OnlyB = append(c.OnlyB, VocabEntry{ID: -1, Token: tok, Freq: fb})
+		}
+	}
+	if union := len(va) + len(vb) - c.Shared; union > 0 {
+		c.Jaccard = float64(c.Shared) / float64(union)
+	}
+
+	sortByFreq(c.OnlyA)
+	sortByFreq(c.OnlyB)
+	sort.Slice(c.Disagreements, func(i, j int) bool {
+		di, dj := c.Disagreements[i].diff(), c.Disagreements[j].diff()
+		if di != dj {
+			return di > dj
+		}
+		return c.Disagreements[i].Token < c.Disagreements[j].Token
+	})
+	c.LengthsA = lengthHistogram(va)
+	c.LengthsB = lengthHistogram(vb)
+	return c
+}
+
+func (d TokenDisagreement) diff() int {
+	if d.FreqA > d.FreqB {
+		return d.FreqA - d.FreqB
+	}
+	return d.FreqB - d.FreqA
+}
+
+// normalizedVocab vrátí tokeny slovníku bez značek hranic slov a okrajových
+// mezer s jejich četnostmi. Tokeny, ze kterých nic nezbude, se vynechají.
+func normalizedVocab(r *Result) map[string]int {
+	vocab := make(map[string]int, len(r.Vocab))
+	for _, v := range r.Vocab {
+		tok := strings.TrimSpace(stripMarkers(v.Token))
+		if tok == "" {
+			continue
+		}
+		vocab[tok] += v.Freq
+	}
+	return vocab
+}
+
+func sortByFreq(entries []VocabEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Freq != entries[j].Freq {
+			return entries[i].Freq > entries[j].Freq
+		}
+		return entries[i].Token < entries[j].Token
+	})
+}
+
+func lengthHistogram(vocab map[string]int) []int {
+	var hist []int
+	for tok := range vocab {
+		n := utf8.RuneCountInString(tok)
+		for len(hist) <= n {
+			hist = append(hist, 0)
+		}
+		hist[n]++
+	}
+	return hist
+}
+
+// Print vypíše souhrn srovnání s nejvýše top tokeny v každém seznamu.
+func (c VocabComparison) Print(w io.Writer, top int) {
+	fmt.Fprintf(w, "=== Srovnání slovníků: %s × %s ===\n", c.NameA, c.NameB)
+	fmt.Fprintf(w, "Velikost: %d × %d, společných %d, Jaccard %.3f\n", c.SizeA, c.SizeB, c.Shared, c.Jaccard)
+
+	fmt.Fprintf(w, "%-6s %8s %8s\n", "Délka", c.NameA, c.NameB)
+	for n := 1; n < max(len(c.LengthsA), len(c.LengthsB)); n++ {
+		fmt.Fprintf(w, "%-6d %8d %8d\n", n, histAt(c.LengthsA, n), histAt(c.LengthsB, n))
+	}
+
+	printEntries := func(title string, entries []VocabEntry) {
+		fmt.Fprintf(w, "%s (%d):\n", title, len(entries))
+		for i, e := range entries[:min(top, len(entries))] {
+			fmt.Fprintf(w, "%2d. %q — %d\n", i+1, e.Token, e.Freq)
+		}
+	}
+	printEntries("Jen v "+c.NameA, c.OnlyA)
+	printEntries("Jen v "+c.NameB, c.OnlyB)
+
+	fmt.Fprintf(w, "Největší rozdíly četností:\n")
+	for i, d := range c.Disagreements[:min(top, len(c.Disagreements))] {
+		fmt.Fprintf(w, "%2d. %q — %d × %d\n", i+1, d.Token, d.FreqA, d.FreqB)
+	}
+}
+
+func histAt(hist []int, n int) int {
+	if n < len(hist) {
+		return hist[n]
+	}
+	return 0
+}
+
+// WriteCSV zapíše srovnání po tokenech: token, délka, četnost v A a v B
+// a kde se token vyskytuje (both, a, b). Řádky jsou seřazené podle tokenu.
+func (c VocabComparison) WriteCSV(w io.Writer) error {
+	type row struct {
+		token        string
+		freqA, freqB int
+		in           string
+	}
+	rows := make([]row, 0, c.SizeA+c.SizeB-c.Shared)
+	for _, d := range c.Disagreements {
+		rows = append(rows, row{d.Token, d.FreqA, d.FreqB, "both"})
+	}
+	for _, e := range c.OnlyA {
+		rows = append(rows, row{e.Token, e.Freq, 0, "a"})
+	}
+	for _, e := range c.OnlyB {
+		rows = append(rows, row{e.Token, 0, e.Freq, "b"})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].token < rows[j].token })
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"token", "length", "freq_" + c.NameA, "freq_" + c.NameB, "in"})
+	for _, r := range rows {
+		cw.Write([]string{
+			r.token,
+			strconv.Itoa(utf8.RuneCountInString(r.token)),
+			strconv.Itoa(r.freqA),
+			strconv.Itoa(r.freqB),
+			r.in,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ConcordanceSort určuje řazení řádků konkordance.
+type ConcordanceSort int
+
+const (
+	// SortByPosition zachová pořadí výskytů v textu.
+	SortByPosition ConcordanceSort = iota
+	// SortByLeft řadí podle levého kontextu od slova nejblíž shodě.
+	SortByLeft
+	// SortByRight řadí podle pravého kontextu.
+	SortByRight
+)
+
+// ConcordanceOptions jsou parametry hledání konkordance.
+type ConcordanceOptions struct {
+	// Regex určuje, že dotaz je regulární výraz; jinak je to slovo nebo fráze,
+	// která se hledá jako celá slova bez ohledu na mezery mezi nimi.
+	Regex bool
+	// CaseSensitive vypne hledání bez ohledu na velikost písmen.
+	CaseSensitive bool
+	// Width je šířka levého i pravého kontextu ve znacích (0 = 40).
+	Width int
+	Sort  ConcordanceSort
+	// Limit omezí počet řádků po seřazení (0 = všechny).
+	Limit int
+}
+
+// ConcordanceLine je jeden výskyt dotazu s kontextem. Bílé znaky v kontextu
+// jsou nahrazeny mezerou, aby se řádky daly zarovnat.
+type ConcordanceLine struct {
+	Offset int // bytový offset shody v textu
+	Left   string
+	Match  string
+	Right  string
+}
+
+// Concordance najde výskyty dotazu v textu (keyword in context).
+func Concordance(text, query string, opts ConcordanceOptions) ([]ConcordanceLine, error) {
+	re, err := concordancePattern(query, opts)
+	if err != nil {
+		return nil, err
+	}
+	width := opts.Width
+	if width <= 0 {
+		width = 40
+	}
+
+	var matches [][]int
+	if opts.Regex {
+		matches = re.FindAllStringIndex(text, -1)
+	} else {
+		matches = wholeWordMatches(re, text)
+	}
+
+	var lines []ConcordanceLine
+	for _, loc := range matches {
+		start, end := loc[0], loc[1]
+		if start == end {
+			continue
+		}
+		lines = append(lines, ConcordanceLine{
+			Offset: start,
+			Left:   flattenSpace(lastRunes(text[:start], width)),
+			Match:  flattenSpace(text[start:end]),
+			Right:  flattenSpace(firstRunes(text[end:], width)),
+		})
+	}
+
+	switch opts.Sort {
+	case SortByLeft:
+		sort.SliceStable(lines, func(i, j int) bool { return leftKey(lines[i].Left) < leftKey(lines[j].Left) })
+	case SortByRight:
+		sort.SliceStable(lines, func(i, j int) bool {
+			return strings.ToLower(strings.TrimSpace(lines[i].Right)) < strings.ToLower(strings.TrimSpace(lines[j].Right))
+		})
+	}
+	if opts.Limit > 0 && opts.Limit < len(lines) {
+		lines = lines[:opts.Limit]
+	}
+	return lines, nil
+}
+
+// concordancePattern sestaví regulární výraz dotazu.
+func concordancePattern(query string, opts ConcordanceOptions) (*regexp.Regexp, error) {
+	expr := query
+	if !opts.Regex {
+		words := strings.Fields(query)
+		if len(words) == 0 {
+			return nil, fmt.Errorf("konkordance: prázdný dotaz")
+		}
+		for i, w := range words {
+			words[i] = regexp.QuoteMeta(w)
+		}
+		expr = strings.Join(words, `\s+`)
+	}
+	if !opts.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("konkordance: %w", err)
+	}
+	return re, nil
+}
+
+// wholeWordMatches najde shody, které začínají i končí na hranici slova.
+// Po odmítnuté shodě hledá dál od jejího druhého znaku, aby nepřeskočil
+// překrývající se výskyt ("a a" v "ba a a").
+func wholeWordMatches(re *regexp.Regexp, text string) [][]int {
+	var out [][]int
+	for pos := 0; pos < len(text); {
+		loc := re.FindStringIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		if start < end && wholeWord(text, start, end) {
+			out = append(out, []int{start, end})
+			pos = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		pos = start + max(size, 1)
+	}
+	return out
+}
+
+// wholeWord hlásí, zda shoda text[start:end] nezačíná ani nekončí uvnitř slova.
+// (\b v RE2 zná jen ASCII, takže by selhal na české diakritice.)
+func wholeWord(text string, start, end int) bool {
+	before, _ := utf8.DecodeLastRuneInString(text[:start])
+	after, _ := utf8.DecodeRuneInString(text[end:])
+	return !isWordRune(before) && !isWordRune(after)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+func lastRunes(s string, n int) string {
+	for i := len(s); i > 0; {
+		_, size := utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+		if n--; n == 0 {
+			return s[i:]
+		}
+	}
+	return s
+}
+
+func firstRunes(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+func flattenSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// leftKey vrátí slova levého kontextu v obráceném pořadí, aby se řadilo podle
+// slova těsně před shodou.
+func leftKey(left string) string {
+	words := strings.Fields(strings.ToLower(left))
+	for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+		words[i], words[j] = words[j], words[i]
+	}
+	return strings.Join(words, " ")
+}
+
+// WriteConcordance vypíše řádky zarovnané na shodu: levý kontext doprava,
+// pravý doleva, obojí na šířku width znaků.
+func WriteConcordance(w io.Writer, lines []ConcordanceLine, width int) error {
+	if width <= 0 {
+		width = 40
+	}
+	for _, l := range lines {
+		pad := max(0, width-utf8.RuneCountInString(l.Left))
+		if _, err := fmt.Fprintf(w, "%s%s[%s]%s\n", strings.Repeat(" ", pad), l.Left, l.Match, l.Right); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s ConcordanceSort) String() string {
+	switch s {
+	case SortByPosition:
+		return "none"
+	case SortByLeft:
+		return "left"
+	case SortByRight:
+		return "right"
+	}
+	return fmt.Sprintf("ConcordanceSort(%d)", int(s))
+}
+
+func (s ConcordanceSort) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *ConcordanceSort) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "none", "":
+		*s = SortByPosition
+	case "left":
+		*s = SortByLeft
+	case "right":
+		*s = SortByRight
+	default:
+		return fmt.Errorf("neznámé řazení konkordance %q (none, left, right)", text)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MergeConstraint rozhoduje, zda se smí kandidátní pár sloučit. Zakázaný pár se
+// při učení vůbec nepočítá, takže se nikdy nestane merge operací. Nil povolí vše.
+type MergeConstraint func(m Merge) bool
+
+func (c MergeConstraint) allows(m Merge) bool {
+	return c == nil || c(m)
+}
+
+// CharClass je třída znaku pro omezení merge operací.
+type CharClass int
+
+const (
+	Letter CharClass = iota
+	Digit
+	Punct
+	Space
+	Other
+)
+
+func classOf(r rune) CharClass {
+	switch {
+	case unicode.IsLetter(r) || unicode.IsMark(r):
+		return Letter
+	case unicode.IsDigit(r):
+		return Digit
+	case unicode.IsSpace(r):
+		return Space
+	case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		return Punct
+	}
+	return Other
+}
+
+// stripMarkers odstraní z tokenu značky hranic slov, které do žádné třídy nepatří.
+func stripMarkers(s string) string {
+	s = strings.TrimSuffix(s, "<end_of_word>")
+	return strings.TrimPrefix(s, spaceMarker)
+}
+
+// tokenClasses vrátí množinu tříd znaků tokenu bez mezer a značek hranic slov.
+func tokenClasses(s string) map[CharClass]bool {
+	classes := make(map[CharClass]bool)
+	for _, r := range stripMarkers(s) {
+		if c := classOf(r); c != Space {
+			classes[c] = true
+		}
+	}
+	return classes
+}
+
+// SeparateClasses zakáže merge, kde jedna strana obsahuje znak některé z tříd
+// a druhá strana znak jiné třídy. Např. SeparateClasses(Digit, Punct) nechá
+// čísla i interpunkci slučovat jen mezi sebou, nikdy s kmenem slova.
+// Mezery a značky hranic slov se neposuzují, na ty je NoBoundaryCrossing.
+func SeparateClasses(classes ...CharClass) MergeConstraint {
+	separate := make(map[CharClass]bool, len(classes))
+	for _, c := range classes {
+		separate[c] = true
+	}
+	return func(m Merge) bool {
+		a, b := tokenClasses(m.A), tokenClasses(m.B)
+		for ca := range a {
+			for cb := range b {
+				if ca != cb && (separate[ca] || separate[cb]) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// NoBoundaryCrossing zakáže merge, po kterém by token obsahoval znak hraniční
+// třídy jinde než na svém začátku. S třídou Space tak ByteBPE smí vytvořit
+// " slovo", ale ne "slovo " nebo "a b".
+func NoBoundaryCrossing(boundary CharClass) MergeConstraint {
+	return func(m Merge) bool {
+		inner := false
+		for _, r := range m.A + m.B {
+			if classOf(r) != boundary {
+				inner = true
+			} else if inner {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AllOf povolí merge, jen když ho povolí všechna omezení.
+func AllOf(constraints ...MergeConstraint) MergeConstraint {
+	return func(m Merge) bool {
+		for _, c := range constraints {
+			if !c.allows(m) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MergePolicy je pojmenovaná sada vestavěných omezení pro konfiguraci.
+type MergePolicy int
+
+const (
+	// AnyMerge nic neomezuje.
+	AnyMerge MergePolicy = iota
+	// SeparateDigitsPunct nelepí čísla a interpunkci ke slovům.
+	SeparateDigitsPunct
+	// StrictClasses navíc drží mezery jen na začátku tokenu (pro ByteBPE).
+	StrictClasses
+)
+
+// Constraint vrátí omezení odpovídající politice.
+func (p MergePolicy) Constraint() MergeConstraint {
+	switch p {
+	case SeparateDigitsPunct:
+		return SeparateClasses(Digit, Punct, Other)
+	case StrictClasses:
+		return AllOf(SeparateClasses(Digit, Punct, Other), NoBoundaryCrossing(Space))
+	}
+	return nil
+}
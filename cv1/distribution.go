@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// DefaultRenyiAlpha je řád Rényiho entropie, který podle Zouhar et al. (2023)
+// nejlépe koreluje s kvalitou překladu.
+const DefaultRenyiAlpha = 2.5
+
+// TokenDistribution popisuje unigramové rozdělení tokenů v tokenizované sekvenci.
+type TokenDistribution struct {
+	Tokens int // délka sekvence
+	Types  int // počet různých tokenů v sekvenci
+	Vocab  int // velikost slovníku (včetně tokenů, které v sekvenci nejsou)
+	// Entropy je Shannonova entropie unigramového rozdělení v bitech.
+	Entropy float64
+	// Efficiency je Shannonova entropie dělená log2 velikosti slovníku.
+	Efficiency float64
+	// Renyi je Rényiho entropie řádu Alpha v bitech, RenyiEfficiency totéž
+	// dělené log2 velikosti slovníku.
+	Alpha           float64
+	Renyi           float64
+	RenyiEfficiency float64
+	// RareShare je podíl slovníku použitý méně než RareBelow krát.
+	RareBelow int
+	RareShare float64
+	// Lengths je histogram délek tokenů v sekvenci ve znacích bez značek
+	// hranic slov (index = délka).
+	Lengths []int
+}
+
+// Distribution spočítá metriky rozdělení tokenů ze slovníku a sekvence. Slovník
+// má být celý naučený slovník včetně nepoužitých tokenů (viz
+// Result.Distribution); slovník z Tokenize obsahuje jen tokeny sekvence, takže
+// by RareShare nepoužité merge nezapočítal. Tokeny sekvence, které ve slovníku
+// chybí, se do něj přidají. Alpha <= 0 znamená DefaultRenyiAlpha.
+func Distribution(vocab, seq []string, alpha float64, rareBelow int) TokenDistribution {
+	if alpha <= 0 {
+		alpha = DefaultRenyiAlpha
+	}
+	counts := tokenCounts(seq)
+	d := TokenDistribution{Tokens: len(seq), Types: len(counts), Alpha: alpha, RareBelow: rareBelow}
+
+	inVocab := make(map[string]struct{}, len(vocab))
+	for _, v := range vocab {
+		inVocab[v] = struct{}{}
+	}
+	for tok := range counts {
+		inVocab[tok] = struct{}{}
+	}
+	d.Vocab = len(inVocab)
+
+	rare := 0
+	for tok := range inVocab {
+		if counts[tok] < rareBelow {
+			rare++
+		}
+	}
+	if d.Vocab > 0 {
+		d.RareShare = float64(rare) / float64(d.Vocab)
+	}
+
+	if d.Tokens == 0 {
+		return d
+	}
+	n := float64(d.Tokens)
+	var powSum float64
+	for tok, c := range counts {
+		p := float64(c) / n
+		d.Entropy -= p * math.Log2(p)
+		powSum += math.Pow(p, alpha)
+
+		l := utf8.RuneCountInString(stripMarkers(tok))
+		for len(d.Lengths) <= l {
+			d.Lengths = append(d.Lengths, 0)
+		}
+		d.Lengths[l] += c
+	}
+	if alpha == 1 {
+		d.Renyi = d.Entropy
+	} else {
+		d.Renyi = math.Log2(powSum) / (1 - alpha)
+	}
+	if d.Vocab > 1 {
+		logV := math.Log2(float64(d.Vocab))
+		d.Efficiency = d.Entropy / logV
+		d.RenyiEfficiency = d.Renyi / logV
+	}
+	return d
+}
+
+// Distribution spočítá metriky rozdělení tokenů výsledku nad celým naučeným
+// slovníkem, včetně tokenů s nulovou četností.
+func (r *Result) Distribution(alpha float64, rareBelow int) TokenDistribution {
+	vocab := make([]string, len(r.Vocab))
+	for i, v := range r.Vocab {
+		vocab[i] = v.Token
+	}
+	return Distribution(vocab, r.Sequence(), alpha, rareBelow)
+}
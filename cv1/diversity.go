@@ -0,0 +1,113 @@
+package main
+
+import "math"
+
+// Parametry MTLD a HD-D podle McCarthy & Jarvis (2010).
+const (
+	mtldThreshold = 0.72
+	hddSample     = 42
+)
+
+// LexicalDiversity jsou standardní míry lexikální rozmanitosti textu.
+type LexicalDiversity struct {
+	TTR     float64 `json:"ttr"`      // typy / tokeny
+	RootTTR float64 `json:"root_ttr"` // Guiraudův index: typy / √tokeny
+	Hapax   int     `json:"hapax"`    // slova s četností 1
+	Dis     int     `json:"dis"`      // slova s četností 2
+	// YuleK je Yuleova charakteristika K = 10⁴ · (Σ f² - N) / N²; čím menší,
+	// tím rozmanitější text.
+	YuleK float64 `json:"yule_k"`
+	// SimpsonD je pravděpodobnost, že dvě náhodně vybraná slova (bez vracení)
+	// jsou stejná.
+	SimpsonD float64 `json:"simpson_d"`
+	// MTLD je průměrná délka úseku, ve kterém TTR neklesne pod 0,72
+	// (průměr průchodu zepředu a zezadu).
+	MTLD float64 `json:"mtld"`
+	// HDD je očekávaný počet typů v náhodném vzorku 42 slov dělený 42.
+	HDD float64 `json:"hdd"`
+}
+
+// computeDiversity spočítá míry rozmanitosti ze slov textu v pořadí a jejich četností.
+func computeDiversity(tokens []string, freq map[string]int) LexicalDiversity {
+	var d LexicalDiversity
+	n := float64(len(tokens))
+	if n == 0 {
+		return d
+	}
+	v := float64(len(freq))
+	d.TTR = v / n
+	d.RootTTR = v / math.Sqrt(n)
+
+	var sumSq, sumPairs float64
+	for _, c := range freq {
+		switch c {
+		case 1:
+			d.Hapax++
+		case 2:
+			d.Dis++
+		}
+		f := float64(c)
+		sumSq += f * f
+		sumPairs += f * (f - 1)
+	}
+	d.YuleK = 1e4 * (sumSq - n) / (n * n)
+	if n > 1 {
+		d.SimpsonD = sumPairs / (n * (n - 1))
+	}
+
+	reversed := make([]string, len(tokens))
+	for i, w := range tokens {
+		reversed[len(tokens)-1-i] = w
+	}
+	d.MTLD = (mtldPass(tokens) + mtldPass(reversed)) / 2
+	d.HDD = hdd(freq, len(tokens))
+	return d
+}
+
+// mtldPass spočítá MTLD jedním průchodem: počet faktorů, po kterých TTR
+// klesne na práh, včetně poměrné části nedokončeného faktoru.
+func mtldPass(tokens []string) float64 {
+	var factors float64
+	seen := make(map[string]struct{})
+	count := 0
+	ttr := 1.0
+	for _, w := range tokens {
+		seen[w] = struct{}{}
+		count++
+		ttr = float64(len(seen)) / float64(count)
+		if ttr <= mtldThreshold {
+			factors++
+			seen = make(map[string]struct{})
+			count = 0
+			ttr = 1
+		}
+	}
+	if count > 0 {
+		factors += (1 - ttr) / (1 - mtldThreshold)
+	}
+	if factors == 0 {
+		return float64(len(tokens))
+	}
+	return float64(len(tokens)) / factors
+}
+
+// hdd spočítá HD-D: pro každý typ pravděpodobnost (hypergeometrické rozdělení),
+// že se objeví ve vzorku 42 slov, sečtenou a dělenou velikostí vzorku.
+// Kratší text se vezme celý.
+func hdd(freq map[string]int, total int) float64 {
+	sample := min(hddSample, total)
+	var sum float64
+	for _, c := range freq {
+		// P(typ ve vzorku chybí) = C(N-f, s) / C(N, s)
+		pNone := 1.0
+		for i := 0; i < sample; i++ {
+			pNone *= float64(total-c-i) / float64(total-i)
+			if pNone <= 0 {
+				pNone = 0
+				break
+			}
+		}
+		sum += 1 - pNone
+	}
+	return sum / float64(sample)
+}
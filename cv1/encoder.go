@@ -0,0 +1,517 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Ranks přiřazuje tokenům naučeného slovníku jejich rank. Nejnižší ranky mají
+// počáteční symboly, po nich následují výsledky merge operací v pořadí učení.
+type Ranks map[string]int
+
+// rankMerges sestaví ranky z počáteční abecedy a merge operací. Pokud merge
+// vytvoří token, který už ve slovníku je, ponechá se jeho nižší rank.
+func rankMerges(alphabet []string, merges []Merge) Ranks {
+	ranks := make(Ranks, len(alphabet)+len(merges))
+	for _, s := range alphabet {
+		if _, ok := ranks[s]; !ok {
+			ranks[s] = len(ranks)
+		}
+	}
+	for _, m := range merges {
+		merged := m.A + m.B
+		if _, ok := ranks[merged]; !ok {
+			ranks[merged] = len(ranks)
+		}
+	}
+	return ranks
+}
+
+// Tokens vrátí tokeny slovníku seřazené podle ranku.
+func (r Ranks) Tokens() []string {
+	tokens := make([]string, 0, len(r))
+	for s := range r {
+		tokens = append(tokens, s)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return r[tokens[i]] < r[tokens[j]]
+	})
+	return tokens
+}
+
+// WithByteFallback vrátí kopii ranků rozšířenou o 256 bytových tokenů
+// <0x00> … <0xFF> pro režim ByteFallback. Dostanou ranky za koncem slovníku.
+func (r Ranks) WithByteFallback() Ranks {
+	out := make(Ranks, len(r)+256)
+	next := 0
+	for s, id := range r {
+		out[s] = id
+		if id >= next {
+			next = id + 1
+		}
+	}
+	for b := 0; b < 256; b++ {
+		tok := byteToken(byte(b))
+		if _, ok := out[tok]; !ok {
+			out[tok] = next
+			next++
+		}
+	}
+	return out
+}
+
+// byteToken zapíše byte jako token pro byte fallback, např. <0xC5>.
+func byteToken(b byte) string {
+	return fmt.Sprintf("<0x%02X>", b)
+}
+
+// UnknownPolicy určuje, co Encoder udělá se znakem, který ve slovníku není.
+type UnknownPolicy int
+
+const (
+	// KeepUnknown ponechá neznámý znak jako samostatný token mimo slovník.
+	KeepUnknown UnknownPolicy = iota
+	// ByteFallback rozloží neznámý znak na UTF-8 byty zapsané jako tokeny
+	// <0xNN>. Slovník je má mít rezervované, viz Ranks.WithByteFallback.
+	ByteFallback
+	// Strict odmítne text s neznámými znaky chybou *UnknownCharsError.
+	Strict
+)
+
+// UnknownChar je znak mimo slovník a jeho bytový offset v kódovaném textu.
+type UnknownChar struct {
+	Char   rune
+	Offset int
+}
+
+// UnknownCharsError vrací Encoder v režimu Strict, obsahuje všechny neznámé znaky.
+type UnknownCharsError struct {
+	Chars []UnknownChar
+}
+
+func (e *UnknownCharsError) Error() string {
+	const limit = 10
+	parts := make([]string, 0, limit)
+	for i, c := range e.Chars {
+		if i == limit {
+			parts = append(parts, fmt.Sprintf("… a dalších %d", len(e.Chars)-limit))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%q (U+%04X) na offsetu %d", c.Char, c.Char, c.Offset))
+	}
+	return fmt.Sprintf("neznámé znaky (%d): %s", len(e.Chars), strings.Join(parts, ", "))
+}
+
+// EncodeMode určuje, jak Encoder segmentuje pre-token.
+type EncodeMode int
+
+const (
+	// MergeOrder slučuje symboly podle ranků, stejně jako při učení BPE.
+	MergeOrder EncodeMode = iota
+	// ShortestPath najde segmentaci s nejmenším počtem tokenů slovníku.
+	ShortestPath
+	// MaxLogProb najde segmentaci s největší součtovou log-pravděpodobností
+	// tokenů podle unigramových četností v Encoder.Counts.
+	MaxLogProb
+)
+
+// Encoder tokenizuje nový text naučeným slovníkem. Text se nejdřív rozdělí
+// na pre-tokeny a každý pre-token na počáteční symboly. V režimu MergeOrder
+// se symboly slučují podle ranků (vždy pár, jehož spojení má nejnižší rank),
+// v ostatních režimech se optimální segmentace hledá dynamickým programováním.
+//
+// S CacheSize > 0 si Encoder pamatuje segmentace naposledy použitých
+// pre-tokenů, takže časté slovo se segmentuje jen jednou. U ByteTokenizeru je
+// pre-tokenem celý vstup. Nastavení se po prvním Encode už nemá měnit; Encode
+// i EncodeBatch lze pak volat souběžně.
+type Encoder struct {
+	Ranks   Ranks
+	Mode    EncodeMode
+	Unknown UnknownPolicy
+	// Counts jsou četnosti tokenů (např. z učení, viz tokenCounts) pro režim MaxLogProb.
+	Counts map[string]int
+	// CacheSize je kapacita LRU cache pre-tokenů (0 = bez cache).
+	CacheSize int
+	// Workers je počet goroutin v EncodeBatch (0 = GOMAXPROCS).
+	Workers int
+
+	pretokenize func(text string) []pretoken
+	suffix      []string // symboly připojené za každý pre-token (značka konce slova)
+
+	cacheOnce sync.Once
+	cache     *lruCache
+
+	dpOnce    sync.Once
+	maxLen    int     // nejdelší token slovníku ve znacích
+	countSum  float64 // součet Counts
+	unseenLog float64 // log-pravděpodobnost tokenu s nulovou četností
+}
+
+// Encoder vrátí enkodér, který dělí text na slova stejně jako učení WordTokenizeru.
+func (t WordTokenizer) Encoder(ranks Ranks) *Encoder {
+	e := &Encoder{Ranks: ranks, pretokenize: t.pretokens}
+	if t.Marker == EndOfWord {
+		e.suffix = []string{"<end_of_word>"}
+	}
+	return e
+}
+
+// Encoder vrátí enkodér, který zpracuje celý text jako jeden pre-token
+// (ByteTokenizer slučuje i přes mezery).
+func (t ByteTokenizer) Encoder(ranks Ranks) *Encoder {
+	return &Encoder{Ranks: ranks, pretokenize: func(text string) []pretoken {
+		return []pretoken{{text: text}}
+	}}
+}
+
+// Encode rozdělí text na tokeny naučeného slovníku. Chybu vrací jen v režimu
+// Strict, a to *UnknownCharsError se všemi znaky mimo slovník.
+func (e *Encoder) Encode(text string) ([]string, error) {
+	var out []string
+	var unknown []UnknownChar
+	cache := e.lru()
+	for _, p := range e.pretokenize(text) {
+		if cache != nil {
+			if toks, ok := cache.get(p.text); ok {
+				out = append(out, toks...)
+				continue
+			}
+		}
+
+		syms, unk := e.symbols(text, p)
+		unknown = append(unknown, unk...)
+		if e.Unknown == Strict && len(unknown) > 0 {
+			continue
+		}
+		toks := e.segment(syms)
+		// pre-tokeny s neznámými znaky se neukládají, aby se vždy nahlásily jejich pozice
+		if cache != nil && len(unk) == 0 {
+			cache.add(p.text, toks)
+		}
+		out = append(out, toks...)
+	}
+	if e.Unknown == Strict && len(unknown) > 0 {
+		return nil, &UnknownCharsError{Chars: unknown}
+	}
+	return out, nil
+}
+
+// EncodeBatch zakóduje texty souběžně ve více goroutinách. Výsledky jsou
+// ve stejném pořadí jako vstup; při chybě vrátí tu z textu s nejnižším indexem.
+func (e *Encoder) EncodeBatch(texts []string) ([][]string, error) {
+	workers := e.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(texts) {
+		workers = len(texts)
+	}
+
+	out := make([][]string, len(texts))
+	errs := make([]error, len(texts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				out[i], errs[i] = e.Encode(texts[i])
+			}
+		}()
+	}
+	for i := range texts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("text %d: %w", i, err)
+		}
+	}
+	return out, nil
+}
+
+// CacheStats vrátí počet zásahů a výpadků cache pre-tokenů.
+func (e *Encoder) CacheStats() (hits, misses int) {
+	if c := e.lru(); c != nil {
+		return c.stats()
+	}
+	return 0, 0
+}
+
+// lru vrátí cache pre-tokenů, při prvním použití ji vytvoří podle CacheSize.
+func (e *Encoder) lru() *lruCache {
+	e.cacheOnce.Do(func() {
+		if e.CacheSize > 0 {
+			e.cache = newLRUCache(e.CacheSize)
+		}
+	})
+	return e.cache
+}
+
+// segment rozdělí počáteční symboly pre-tokenu na tokeny podle zvoleného režimu.
+func (e *Encoder) segment(syms []string) []string {
+	if e.Mode == MergeOrder {
+		return mergeByRank(e.Ranks, syms)
+	}
+	return e.bestPath(syms)
+}
+
+// IDs převede tokeny na jejich ranky. Tokeny mimo slovník dostanou -1.
+func (e *Encoder) IDs(tokens []string) []int {
+	ids := make([]int, len(tokens))
+	for i, s := range tokens {
+		id, ok := e.Ranks[s]
+		if !ok {
+			id = -1
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// symbols rozloží pre-token na počáteční symboly – znaky a případnou značku
+// konce slova. Znak, který ve slovníku není, se rozloží na byty, pokud jsou
+// ve slovníku všechny (tak fungují byte-level slovníky z .tiktoken souborů);
+// jinak je to neznámý znak a naloží se s ním podle e.Unknown.
+func (e *Encoder) symbols(text string, p pretoken) ([]string, []UnknownChar) {
+	syms := make([]string, 0, len(p.text)+len(e.suffix))
+	var unknown []UnknownChar
+	pos := p.off // offset v původním textu (▁ tam může být mezera)
+	for i := 0; i < len(p.text); {
+		orig, size := utf8.DecodeRuneInString(text[pos:])
+		// neplatný UTF-8 byte zůstane sám sebou, ne U+FFFD, aby šel text složit zpět
+		_, n := utf8.DecodeRuneInString(p.text[i:])
+		s := p.text[i : i+n]
+		i += n
+		switch {
+		case e.known(s):
+			syms = append(syms, s)
+		case len(s) > 1 && e.knownBytes(s):
+			for b := 0; b < len(s); b++ {
+				syms = append(syms, s[b:b+1])
+			}
+		default:
+			unknown = append(unknown, UnknownChar{Char: orig, Offset: pos})
+			if e.Unknown == ByteFallback {
+				for b := 0; b < len(s); b++ {
+					syms = append(syms, byteToken(s[b]))
+				}
+			} else {
+				syms = append(syms, s)
+			}
+		}
+		pos += size
+	}
+	return append(syms, e.suffix...), unknown
+}
+
+func (e *Encoder) known(s string) bool {
+	_, ok := e.Ranks[s]
+	return ok
+}
+
+func (e *Encoder) knownBytes(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !e.known(s[i : i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bestPath najde segmentaci symbolů na tokeny slovníku, která má nejmenší cenu:
+// počet tokenů (ShortestPath), nebo zápornou log-pravděpodobnost (MaxLogProb).
+// Symbol, který netvoří žádný token slovníku, se použije samostatně.
+func (e *Encoder) bestPath(syms []string) []string {
+	e.dpOnce.Do(e.prepareDP)
+
+	// kandidáti na tokeny jsou podřetězce spojených symbolů; symbol může mít
+	// víc znaků (<end_of_word>, <0xNN>), délka se proto měří ve znacích
+	n := len(syms)
+	full := joinSymbols(syms)
+	offset := make([]int, n+1)
+	runes := make([]int, n+1)
+	for i, s := range syms {
+		offset[i+1] = offset[i] + len(s)
+		runes[i+1] = runes[i] + utf8.RuneCountInString(s)
+	}
+
+	cost := make([]float64, n+1)
+	from := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		cost[i] = math.Inf(1)
+		// při shodě ceny vyhraje delší token; jeden symbol je kandidát vždy
+		for j := i - 1; j >= 0 && (j == i-1 || runes[i]-runes[j] <= e.maxLen); j-- {
+			tok := full[offset[j]:offset[i]]
+			_, known := e.Ranks[tok]
+			if !known && j < i-1 {
+				continue
+			}
+			if c := cost[j] + e.tokenCost(tok); c <= cost[i] {
+				cost[i] = c
+				from[i] = j
+			}
+		}
+	}
+
+	var out []string
+	for i := n; i > 0; i = from[i] {
+		out = append(out, full[offset[from[i]]:offset[i]])
+	}
+	for l, r := 0, len(out)-1; l < r; l, r = l+1, r-1 {
+		out[l], out[r] = out[r], out[l]
+	}
+	return out
+}
+
+// tokenCost je cena jednoho tokenu v dynamickém programování.
+func (e *Encoder) tokenCost(tok string) float64 {
+	if e.Mode != MaxLogProb {
+		return 1
+	}
+	c, ok := e.Counts[tok]
+	if !ok || c == 0 {
+		return -e.unseenLog
+	}
+	// add-one vyhlazení přes celý slovník
+	return -math.Log((float64(c) + 1) / (e.countSum + float64(len(e.Ranks))))
+}
+
+// prepareDP spočítá jednou pro enkodér délku nejdelšího tokenu a součet četností.
+func (e *Encoder) prepareDP() {
+	e.maxLen = 1
+	for tok := range e.Ranks {
+		if l := utf8.RuneCountInString(tok); l > e.maxLen {
+			e.maxLen = l
+		}
+	}
+	for _, c := range e.Counts {
+		e.countSum += float64(c)
+	}
+	e.unseenLog = math.Log(1 / (e.countSum + float64(len(e.Ranks))))
+}
+
+// tokenCounts spočítá četnosti tokenů v sekvenci (pro režim MaxLogProb).
+func tokenCounts(seq []string) map[string]int {
+	counts := make(map[string]int)
+	for _, s := range seq {
+		counts[s]++
+	}
+	return counts
+}
+
+func joinSymbols(syms []string) string {
+	if len(syms) == 1 {
+		return syms[0]
+	}
+	n := 0
+	for _, s := range syms {
+		n += len(s)
+	}
+	b := make([]byte, 0, n)
+	for _, s := range syms {
+		b = append(b, s...)
+	}
+	return string(b)
+}
+
+// rankNode je uzel seznamu symbolů při slučování podle ranků
+type rankNode struct {
+	val     string
+	prev    int
+	next    int
+	version int // zvýší se při každé změně uzlu, neplatné páry v haldě se tak poznají
+}
+
+// rankPair je kandidát na sloučení uzlu left s jeho následníkem
+type rankPair struct {
+	rank    int
+	left    int
+	right   int
+	version int
+	nextVer int
+}
+
+type rankHeap []rankPair
+
+func (h rankHeap) Len() int { return len(h) }
+func (h rankHeap) Less(i, j int) bool {
+	if h[i].rank == h[j].rank {
+		return h[i].left < h[j].left
+	}
+	return h[i].rank < h[j].rank
+}
+func (h rankHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *rankHeap) Push(x any)   { *h = append(*h, x.(rankPair)) }
+func (h *rankHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// mergeByRank slučuje sousední symboly, dokud existuje pár, jehož spojení je
+// ve slovníku. Vždy se sloučí pár s nejnižším rankem, při shodě ten nejvíc vlevo.
+func mergeByRank(ranks Ranks, syms []string) []string {
+	if len(syms) < 2 {
+		return syms
+	}
+
+	nodes := make([]rankNode, len(syms))
+	for i, s := range syms {
+		nodes[i] = rankNode{val: s, prev: i - 1, next: i + 1}
+	}
+	nodes[len(nodes)-1].next = -1
+
+	h := &rankHeap{}
+	push := func(i int) {
+		if i < 0 || nodes[i].next < 0 {
+			return
+		}
+		j := nodes[i].next
+		if r, ok := ranks[nodes[i].val+nodes[j].val]; ok {
+			heap.Push(h, rankPair{rank: r, left: i, right: j, version: nodes[i].version, nextVer: nodes[j].version})
+		}
+	}
+	for i := range nodes {
+		push(i)
+	}
+
+	removed := make([]bool, len(nodes))
+	for h.Len() > 0 {
+		p := heap.Pop(h).(rankPair)
+		i := p.left
+		j := nodes[i].next
+		// Re-validace (uzly se mohly změnit předchozím merge)
+		if removed[i] || j != p.right || nodes[i].version != p.version || nodes[j].version != p.nextVer {
+			continue
+		}
+
+		nodes[i].val += nodes[j].val
+		nodes[i].version++
+		nodes[i].next = nodes[j].next
+		if nodes[j].next >= 0 {
+			nodes[nodes[j].next].prev = i
+		}
+		removed[j] = true
+
+		push(nodes[i].prev)
+		push(i)
+	}
+
+	result := make([]string, 0, len(syms))
+	for i := 0; i >= 0; i = nodes[i].next {
+		result = append(result, nodes[i].val)
+	}
+	return result
+}
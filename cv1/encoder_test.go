@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEncodeModes(t *testing.T) {
+	// Greedy BPE sloučí nejdřív "bc" a pak už "ab" ani "cd" nepoužije
+	ranks := Ranks{"a": 0, "b": 1, "c": 2, "d": 3, "bc": 4, "ab": 5, "cd": 6}
+	enc := ByteTokenizer{}.Encoder(ranks)
+
+	if got := strings.Join(mustEncode(t, enc, "abcd"), "|"); got != "a|bc|d" {
+		t.Errorf("MergeOrder: %s, očekáváno a|bc|d", got)
+	}
+
+	enc = ByteTokenizer{}.Encoder(ranks)
+	enc.Mode = ShortestPath
+	if got := strings.Join(mustEncode(t, enc, "abcd"), "|"); got != "ab|cd" {
+		t.Errorf("ShortestPath: %s, očekáváno ab|cd", got)
+	}
+
+	// Neznámý symbol zůstane samostatně
+	if got := strings.Join(mustEncode(t, enc, "abxcd"), "|"); got != "ab|x|cd" {
+		t.Errorf("ShortestPath s neznámým znakem: %s", got)
+	}
+
+	// Délka tokenu se měří ve znacích i u víceznakových symbolů: token
+	// "ab<end_of_word>" je nejdelší ve slovníku a musí zůstat kandidátem
+	wordEnc := WordTokenizer{}.Encoder(Ranks{"a": 0, "b": 1, "<end_of_word>": 2, "ab<end_of_word>": 3})
+	wordEnc.Mode = ShortestPath
+	if got := strings.Join(mustEncode(t, wordEnc, "ab b"), "|"); got != "ab<end_of_word>|b|<end_of_word>" {
+		t.Errorf("ShortestPath se značkou konce slova: %s", got)
+	}
+
+	// Při stejném počtu tokenů rozhodnou četnosti
+	enc = ByteTokenizer{}.Encoder(ranks)
+	enc.Mode = MaxLogProb
+	enc.Counts = map[string]int{"a": 100, "bc": 100, "d": 100, "ab": 1, "cd": 1}
+	if got := strings.Join(mustEncode(t, enc, "abcd"), "|"); got != "a|bc|d" {
+		t.Errorf("MaxLogProb: %s, očekáváno a|bc|d", got)
+	}
+}
+
+func TestEncodeNeznameZnaky(t *testing.T) {
+	ranks := WordTokenizer{}.Train("kočka kočky", 10).Ranks()
+	text := "kočka žije"
+
+	// Výchozí chování: neznámé znaky zůstanou jako samostatné tokeny mimo slovník
+	enc := WordTokenizer{}.Encoder(ranks)
+	seq := mustEncode(t, enc, text)
+	if ids := enc.IDs(seq); !containsInt(ids, -1) {
+		t.Errorf("očekáván token mimo slovník v %q", seq)
+	}
+
+	// Byte fallback: ž (C5 BE) a j, i, e jsou mimo slovník
+	enc = WordTokenizer{}.Encoder(ranks.WithByteFallback())
+	enc.Unknown = ByteFallback
+	seq = mustEncode(t, enc, text)
+	if !strings.Contains(strings.Join(seq, " "), "<0xC5> <0xBE>") {
+		t.Errorf("očekávány bytové tokeny pro ž, dostáno %q", seq)
+	}
+	if ids := enc.IDs(seq); containsInt(ids, -1) {
+		t.Errorf("všechny tokeny mají být ve slovníku, dostáno %q", seq)
+	}
+
+	// Neplatný UTF-8 byte se zakóduje jako on sám, ne jako byty U+FFFD
+	seq = mustEncode(t, enc, "kočka\xff")
+	if !strings.Contains(strings.Join(seq, " "), "<0xFF>") || strings.Contains(strings.Join(seq, " "), "<0xEF>") {
+		t.Errorf("očekáván bytový token <0xFF>, dostáno %q", seq)
+	}
+	enc = WordTokenizer{}.Encoder(ranks)
+	if got := strings.Join(mustEncode(t, enc, "ko\xffčka"), ""); got != "ko\xffčka<end_of_word>" {
+		t.Errorf("neznámý byte se změnil: %q", got)
+	}
+
+	// Strict: chyba se seznamem znaků a jejich offsetů
+	enc = WordTokenizer{Marker: SpacePrefix}.Encoder(WordTokenizer{Marker: SpacePrefix}.Train("kočka kočky", 10).Ranks())
+	enc.Unknown = Strict
+	_, err := enc.Encode(text)
+	var unk *UnknownCharsError
+	if !errors.As(err, &unk) {
+		t.Fatalf("očekávána UnknownCharsError, dostáno %v", err)
+	}
+	want := []UnknownChar{{'ž', 7}, {'i', 9}, {'j', 10}, {'e', 11}}
+	if len(unk.Chars) != len(want) {
+		t.Fatalf("neznámé znaky %v, očekáváno %v", unk.Chars, want)
+	}
+	for i, c := range want {
+		if unk.Chars[i] != c {
+			t.Errorf("neznámý znak %d: %v, očekáváno %v", i, unk.Chars[i], c)
+		}
+	}
+	t.Log(err)
+}
+
+func mustEncode(t *testing.T, enc *Encoder, text string) []string {
+	t.Helper()
+	seq, err := enc.Encode(text)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return seq
+}
+
+func containsInt(xs []int, x int) bool {
+	for _, v := range xs {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+// TestOptimalniKodovani porovná počet tokenů při přehrání merge operací
+// a při optimální segmentaci stejným slovníkem.
+func TestOptimalniKodovani(t *testing.T) {
+	text := truncateText(loadDataset(t), 20000)
+	numWords := len(strings.Fields(text))
+
+	type model struct {
+		name    string
+		tok     Tokenizer
+		encoder func(Ranks) *Encoder
+	}
+	word := WordTokenizer{}
+	sp := WordTokenizer{Marker: SpacePrefix}
+	byteTok := ByteTokenizer{}
+	models := []model{
+		{"WordBPE", word, word.Encoder},
+		{"WordBPE ▁", sp, sp.Encoder},
+		{"ByteBPE", byteTok, byteTok.Encoder},
+	}
+
+	t.Logf("=== Optimální kódování (K=%d) ===", mergeOps)
+	t.Logf("%-12s %15s %15s %15s %12s", "", "Merge pořadí", "Nejkratší", "Max log-p", "Ztráta")
+	for _, m := range models {
+		res := m.tok.Train(text, mergeOps)
+		ranks := res.Ranks()
+
+		greedy := m.encoder(ranks)
+		shortest := m.encoder(ranks)
+		shortest.Mode = ShortestPath
+		maxProb := m.encoder(ranks)
+		maxProb.Mode = MaxLogProb
+		maxProb.Counts = tokenCounts(res.Sequence())
+
+		g := len(mustEncode(t, greedy, text))
+		s := len(mustEncode(t, shortest, text))
+		p := len(mustEncode(t, maxProb, text))
+		t.Logf("%-12s %15.3f %15.3f %15.3f %11.2f%%", m.name,
+			float64(g)/float64(numWords), float64(s)/float64(numWords), float64(p)/float64(numWords),
+			float64(g-s)/float64(g)*100)
+
+		if s > g {
+			t.Errorf("%s: nejkratší segmentace má %d tokenů, greedy jen %d", m.name, s, g)
+		}
+	}
+}
+
+func TestEncodeBatch(t *testing.T) {
+	text := loadDataset(t)
+	texts := splitIntoChunks(text, 50)
+	ranks := WordTokenizer{}.Train(text, 200).Ranks()
+
+	plain := WordTokenizer{}.Encoder(ranks)
+	cached := WordTokenizer{}.Encoder(ranks)
+	cached.CacheSize = 1000
+	cached.Workers = 4
+
+	batch, err := cached.EncodeBatch(texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != len(texts) {
+		t.Fatalf("EncodeBatch vrátil %d výsledků, očekáváno %d", len(batch), len(texts))
+	}
+	for i, s := range texts {
+		want := strings.Join(mustEncode(t, plain, s), " ")
+		if got := strings.Join(batch[i], " "); got != want {
+			t.Fatalf("text %d: EncodeBatch se liší od Encode", i)
+		}
+	}
+
+	hits, misses := cached.CacheStats()
+	t.Logf("Cache: %d zásahů, %d výpadků", hits, misses)
+	if hits == 0 {
+		t.Error("očekávány zásahy cache")
+	}
+
+	// Chyba v režimu Strict nese index textu
+	cached = WordTokenizer{}.Encoder(ranks)
+	cached.Unknown = Strict
+	if _, err := cached.EncodeBatch([]string{texts[0], "ж"}); err == nil || !strings.HasPrefix(err.Error(), "text 1:") {
+		t.Errorf("očekávána chyba textu 1, dostáno %v", err)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	c := newLRUCache(2)
+	c.add("a", []string{"a"})
+	c.add("b", []string{"b"})
+	c.get("a") // "b" je teď nejdéle nepoužitý
+	c.add("c", []string{"c"})
+
+	if _, ok := c.get("b"); ok {
+		t.Error("b měl být z cache vyhozen")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := c.get(k); !ok {
+			t.Errorf("%s chybí v cache", k)
+		}
+	}
+}
+
+// splitIntoChunks rozdělí text na úseky po n slovech.
+func splitIntoChunks(text string, n int) []string {
+	fields := strings.Fields(text)
+	var chunks []string
+	for i := 0; i < len(fields); i += n {
+		end := i + n
+		if end > len(fields) {
+			end = len(fields)
+		}
+		chunks = append(chunks, strings.Join(fields[i:end], " "))
+	}
+	return chunks
+}
+
+var (
+	benchOnce   sync.Once
+	benchRanks  Ranks
+	benchChunks []string
+)
+
+func loadBenchData(b *testing.B) (Ranks, []string) {
+	b.Helper()
+	benchOnce.Do(func() {
+		text := truncateText(loadDataset(b), 200000)
+		benchRanks = WordTokenizer{}.Train(text, mergeOps).Ranks()
+		benchChunks = splitIntoChunks(text, 100)
+	})
+	return benchRanks, benchChunks
+}
+
+func BenchmarkEncode(b *testing.B) {
+	ranks, chunks := loadBenchData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc := WordTokenizer{}.Encoder(ranks)
+		for _, s := range chunks {
+			if _, err := enc.Encode(s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkEncodeCached(b *testing.B) {
+	ranks, chunks := loadBenchData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc := WordTokenizer{}.Encoder(ranks)
+		enc.CacheSize = 10000
+		for _, s := range chunks {
+			if _, err := enc.Encode(s); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkEncodeBatch(b *testing.B) {
+	ranks, chunks := loadBenchData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc := WordTokenizer{}.Encoder(ranks)
+		enc.CacheSize = 10000
+		if _, err := enc.EncodeBatch(chunks); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// GoldSegmentation mapuje slovo na jeho zlaté morfologické analýzy. Slovo může
+// mít víc alternativních analýz, každá je seznam morfů.
+type GoldSegmentation map[string][][]string
+
+// BoundaryScore je přesnost, úplnost a F1 hranic mezi morfy, mikro-průměrované
+// přes všechna vyhodnocená slova.
+type BoundaryScore struct {
+	Precision float64
+	Recall    float64
+	F1        float64
+	Words     int // počet vyhodnocených slov (ze zlatého standardu nalezených v textu)
+	Correct   int // správně nalezené hranice
+	Predicted int // hranice navržené tokenizerem
+	Gold      int // hranice ve zlatém standardu
+}
+
+// ReadGoldSegmentation načte zlatou segmentaci ve formátu Morpho Challenge:
+// na každém řádku slovo, tabulátor a morfy oddělené mezerou; alternativní
+// analýzy se oddělují čárkou. Prázdné řádky a řádky začínající # se přeskočí.
+//
+//	přípravek	pří prav ek
+//	použití	po uži tí, použi tí
+func ReadGoldSegmentation(r io.Reader) (GoldSegmentation, error) {
+	gold := make(GoldSegmentation)
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		word, analyses, ok := strings.Cut(text, "\t")
+		if !ok {
+			return nil, fmt.Errorf("zlatá segmentace: řádek %d: chybí tabulátor", line)
+		}
+		word = strings.ToLower(strings.TrimSpace(word))
+		for _, a := range strings.Split(analyses, ",") {
+			morphs := strings.Fields(strings.ToLower(a))
+			if strings.Join(morphs, "") != word {
+				return nil, fmt.Errorf("zlatá segmentace: řádek %d: morfy %q nedávají slovo %q", line, morphs, word)
+			}
+			gold[word] = append(gold[word], morphs)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return gold, nil
+}
+
+// loadGoldSegmentation načte zlatou segmentaci ze souboru na dané cestě.
+func loadGoldSegmentation(path string) (GoldSegmentation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGoldSegmentation(f)
+}
+
+// EvaluateBoundaries natrénuje tokenizer na textu a porovná segmentaci slov
+// se zlatým standardem.
+func EvaluateBoundaries(tok Tokenizer, text string, k int, gold GoldSegmentation) (BoundaryScore, error) {
+	pred, err := segmentWords(tok.Train(text, k).Sequence(), text)
+	if err != nil {
+		return BoundaryScore{}, err
+	}
+	return scoreBoundaries(pred, gold), nil
+}
+
+// segmentWords zarovná sekvenci tokenů s textem a pro každé slovo vrátí jeho
+// segmentaci při prvním výskytu. Z tokenů se odstraní značky hranic slov
+// (<end_of_word>, ▁) i mezery, takže funguje pro tokenizery, které slučují
+// přes hranice slov (ByteBPE) – takový token se na hranici slova rozdělí.
+func segmentWords(seq []string, text string) (map[string][]string, error) {
+	// Pozice hranic tokenů v textu bez mezer (počítáno ve znacích)
+	boundaries := make(map[int]struct{}, len(seq))
+	pos := 0
+	for _, tok := range seq {
+		pos += len([]rune(stripWordMarkers(tok)))
+		boundaries[pos] = struct{}{}
+	}
+
+	fields := strings.Fields(text)
+	total := 0
+	for _, w := range fields {
+		total += len([]rune(w))
+	}
+	if total != pos {
+		return nil, fmt.Errorf("sekvence tokenů pokrývá %d znaků, text bez mezer má %d", pos, total)
+	}
+
+	result := make(map[string][]string)
+	start := 0
+	for _, w := range fields {
+		r := []rune(w)
+		if _, seen := result[w]; !seen {
+			var seg []string
+			last := 0
+			for i := 1; i < len(r); i++ {
+				if _, ok := boundaries[start+i]; ok {
+					seg = append(seg, string(r[last:i]))
+					last = i
+				}
+			}
+			result[w] = append(seg, string(r[last:]))
+		}
+		start += len(r)
+	}
+	return result, nil
+}
+
+// stripWordMarkers odstraní z tokenu značky hranic slov a bílé znaky.
+func stripWordMarkers(tok string) string {
+	tok = strings.ReplaceAll(tok, "<end_of_word>", "")
+	tok = strings.ReplaceAll(tok, spaceMarker, "")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, tok)
+}
+
+// scoreBoundaries porovná navržené segmentace se zlatým standardem. Slova, která
+// v predikci chybí, se nevyhodnocují. Má-li slovo víc zlatých analýz, použije se
+// ta, se kterou se predikce shoduje v nejvíce hranicích (při shodě ta s menším
+// počtem hranic).
+func scoreBoundaries(pred map[string][]string, gold GoldSegmentation) BoundaryScore {
+	var s BoundaryScore
+	for w, analyses := range gold {
+		seg, ok := pred[w]
+		if !ok {
+			continue
+		}
+		predicted := morphBoundaries(seg)
+
+		bestCorrect, bestGold := -1, 0
+		for _, a := range analyses {
+			g := morphBoundaries(a)
+			correct := 0
+			for b := range predicted {
+				if _, ok := g[b]; ok {
+					correct++
+				}
+			}
+			if correct > bestCorrect || (correct == bestCorrect && len(g) < bestGold) {
+				bestCorrect, bestGold = correct, len(g)
+			}
+		}
+
+		s.Words++
+		s.Correct += bestCorrect
+		s.Predicted += len(predicted)
+		s.Gold += bestGold
+	}
+
+	if s.Predicted > 0 {
+		s.Precision = float64(s.Correct) / float64(s.Predicted)
+	}
+	if s.Gold > 0 {
+		s.Recall = float64(s.Correct) / float64(s.Gold)
+	}
+	if s.Precision+s.Recall > 0 {
+		s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+	}
+	return s
+}
+
+// morphBoundaries vrátí pozice hranic mezi morfy (ve znacích od začátku slova).
+func morphBoundaries(morphs []string) map[int]struct{} {
+	b := make(map[int]struct{}, len(morphs))
+	pos := 0
+	for i, m := range morphs {
+		pos += len([]rune(m))
+		if i < len(morphs)-1 {
+			b[pos] = struct{}{}
+		}
+	}
+	return b
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSegmentWords(t *testing.T) {
+	text := "léčivý přípravek"
+	// Tokeny ByteBPE mohou přecházet přes mezeru
+	seq := []string{"léč", "ivý p", "ří", "prav", "ek"}
+
+	pred, err := segmentWords(seq, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(pred["léčivý"], "|"); got != "léč|ivý" {
+		t.Errorf("léčivý → %s", got)
+	}
+	if got := strings.Join(pred["přípravek"], "|"); got != "p|ří|prav|ek" {
+		t.Errorf("přípravek → %s", got)
+	}
+
+	if _, err := segmentWords([]string{"léč"}, text); err == nil {
+		t.Error("očekávána chyba pro sekvenci, která nepokrývá celý text")
+	}
+}
+
+func TestScoreBoundaries(t *testing.T) {
+	gold, err := ReadGoldSegmentation(strings.NewReader("# komentář\npřípravek\tpří prav ek\npoužití\tpo uži tí, použi tí\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pred := map[string][]string{
+		"přípravek": {"p", "ří", "prav", "ek"}, // 2 ze 3 navržených hranic správně
+		"použití":   {"použi", "tí"},           // přesně druhá analýza
+	}
+
+	s := scoreBoundaries(pred, gold)
+	if s.Words != 2 || s.Correct != 3 || s.Predicted != 4 || s.Gold != 3 {
+		t.Fatalf("neočekávané počty: %+v", s)
+	}
+	if s.Precision != 0.75 || s.Recall != 1 {
+		t.Errorf("přesnost %.3f, úplnost %.3f", s.Precision, s.Recall)
+	}
+
+	if _, err := ReadGoldSegmentation(strings.NewReader("slovo\tsl ov\n")); err == nil {
+		t.Error("očekávána chyba pro morfy, které nedávají slovo")
+	}
+}
+
+func TestRozdeleniTokenu(t *testing.T) {
+	// Rovnoměrné rozdělení přes celý slovník má efektivitu 1 pro každý řád
+	d := Distribution([]string{"a", "b", "c", "d"}, strings.Fields("a b c d a b c d"), 2, 1)
+	if math.Abs(d.Entropy-2) > 1e-9 || math.Abs(d.Efficiency-1) > 1e-9 || math.Abs(d.RenyiEfficiency-1) > 1e-9 {
+		t.Errorf("rovnoměrné rozdělení: entropie %.3f, efektivita %.3f, Rényi %.3f", d.Entropy, d.Efficiency, d.RenyiEfficiency)
+	}
+
+	// Nepoužitý token slovníku snižuje efektivitu a počítá se mezi vzácné
+	d = Distribution([]string{"ab", "c", "xyz"}, []string{"ab<end_of_word>", "ab<end_of_word>", "c"}, 0, 1)
+	if d.Vocab != 4 || d.RareShare != 0.5 {
+		t.Errorf("slovník %d, podíl vzácných %.2f, očekáváno 4 a 0.50", d.Vocab, d.RareShare)
+	}
+	if d.Renyi > d.Entropy {
+		t.Errorf("Rényiho entropie řádu %.1f (%.3f) větší než Shannonova (%.3f)", d.Alpha, d.Renyi, d.Entropy)
+	}
+	if histAt(d.Lengths, 2) != 2 || histAt(d.Lengths, 1) != 1 {
+		t.Errorf("histogram délek %v", d.Lengths)
+	}
+
+	// Rozdělení výsledku počítá i naučené merge, které v sekvenci nezůstaly
+	res := WordTokenizer{}.Train("kočka kočky kočce", 10)
+	unused := 0
+	for _, v := range res.Vocab {
+		if v.Freq == 0 {
+			unused++
+		}
+	}
+	d = res.Distribution(0, 1)
+	if unused == 0 || d.Vocab != len(res.Vocab) || d.Vocab != d.Types+unused {
+		t.Errorf("slovník %d (typů %d, nepoužitých %d), výsledek má %d tokenů", d.Vocab, d.Types, unused, len(res.Vocab))
+	}
+	if want := float64(unused) / float64(len(res.Vocab)); math.Abs(d.RareShare-want) > 1e-9 {
+		t.Errorf("podíl vzácných %.3f, očekáváno %.3f", d.RareShare, want)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// KeynessMeasure je míra, podle které se řadí klíčová slova korpusu.
+type KeynessMeasure int
+
+const (
+	// KeynessLL je log-likelihood G² (Rayson & Garside, 2000); kladná, když je
+	// slovo v cílovém korpusu častější, záporná, když je vzácnější.
+	KeynessLL KeynessMeasure = iota
+	// KeynessDiff je %DIFF (Gabrielatos & Marchi, 2012): relativní rozdíl
+	// normalizovaných četností v procentech.
+	KeynessDiff
+	// KeynessLogRatio je binární logaritmus poměru normalizovaných četností
+	// (Hardie, 2014); nulové četnosti se nahradí 0,5.
+	KeynessLogRatio
+)
+
+// Kritické hodnoty G² pro jeden stupeň volnosti.
+var llCritical = []struct {
+	p     float64
+	value float64
+}{
+	{0.0001, 15.13},
+	{0.001, 10.83},
+	{0.01, 6.63},
+	{0.05, 3.84},
+}
+
+// KeyWord je slovo se svými četnostmi v cílovém (A) a referenčním (B) korpusu
+// a mírami keyness.
+type KeyWord struct {
+	Word         string
+	FreqA, FreqB int
+	LL           float64
+	PercentDiff  float64
+	LogRatio     float64
+	// P je nejmenší hladina významnosti z llCritical, kterou G² překračuje
+	// (1, když není významné ani na 0,05).
+	P float64
+}
+
+// Keyness porovná tabulky četností cílového korpusu a a referenčního korpusu b.
+// Vrátí slova, jejichž G² je významné na hladině p (p >= 1 vrátí všechna),
+// seřazená sestupně podle zvolené míry: na začátku slova typická pro a,
+// na konci slova typická pro b. Prázdný korpus je chyba, míry by nebyly
+// definované.
+func Keyness(a, b map[string]int, by KeynessMeasure, p float64) ([]KeyWord, error) {
+	var na, nb float64
+	for _, c := range a {
+		na += float64(c)
+	}
+	for _, c := range b {
+		nb += float64(c)
+	}
+	if na == 0 {
+		return nil, fmt.Errorf("keyness: cílový korpus neobsahuje žádná slova")
+	}
+	if nb == 0 {
+		return nil, fmt.Errorf("keyness: referenční korpus neobsahuje žádná slova")
+	}
+
+	words := make(map[string]struct{}, len(a)+len(b))
+	for w := range a {
+		words[w] = struct{}{}
+	}
+	for w := range b {
+		words[w] = struct{}{}
+	}
+
+	var out []KeyWord
+	for w := range words {
+		k := keyWord(w, a[w], b[w], na, nb)
+		if k.P <= p {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		si, sj := out[i].score(by), out[j].score(by)
+		if si != sj {
+			return si > sj
+		}
+		return out[i].Word < out[j].Word
+	})
+	return out, nil
+}
+
+func keyWord(w string, fa, fb int, na, nb float64) KeyWord {
+	k := KeyWord{Word: w, FreqA: fa, FreqB: fb, P: 1}
+	a, b := float64(fa), float64(fb)
+
+	e1 := na * (a + b) / (na + nb)
+	e2 := nb * (a + b) / (na + nb)
+	var ll float64
+	if a > 0 {
+		ll += a * math.Log(a/e1)
+	}
+	if b > 0 {
+		ll += b * math.Log(b/e2)
+	}
+	ll *= 2
+	if a/na < b/nb {
+		ll = -ll
+	}
+	k.LL = ll
+	for _, c := range llCritical {
+		if math.Abs(ll) >= c.value {
+			k.P = c.p
+			break
+		}
+	}
+
+	normA, normB := a/na, b/nb
+	if normB == 0 {
+		normB = 1e-18
+	}
+	k.PercentDiff = (normA - normB) * 100 / normB
+
+	sa, sb := a, b
+	if sa == 0 {
+		sa = 0.5
+	}
+	if sb == 0 {
+		sb = 0.5
+	}
+	k.LogRatio = math.Log2((sa / na) / (sb / nb))
+	return k
+}
+
+func (k KeyWord) score(by KeynessMeasure) float64 {
+	switch by {
+	case KeynessDiff:
+		return k.PercentDiff
+	case KeynessLogRatio:
+		return k.LogRatio
+	}
+	return k.LL
+}
+
+// significance vrátí hvězdičky podle hladiny významnosti (* 0,05 až **** 0,0001).
+func (k KeyWord) significance() string {
+	for i, c := range llCritical {
+		if k.P == c.p {
+			return strings.Repeat("*", len(llCritical)-i)
+		}
+	}
+	return ""
+}
+
+func (m KeynessMeasure) String() string {
+	switch m {
+	case KeynessLL:
+		return "ll"
+	case KeynessDiff:
+		return "diff"
+	case KeynessLogRatio:
+		return "ratio"
+	}
+	return fmt.Sprintf("KeynessMeasure(%d)", int(m))
+}
+
+func (m KeynessMeasure) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *KeynessMeasure) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "ll", "":
+		*m = KeynessLL
+	case "diff":
+		*m = KeynessDiff
+	case "ratio":
+		*m = KeynessLogRatio
+	default:
+		return fmt.Errorf("neznámá míra keyness %q (ll, diff, ratio)", text)
+	}
+	return nil
+}
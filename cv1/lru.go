@@ -0,0 +1,66 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache je LRU cache segmentací pre-tokenů, bezpečná pro souběžné použití.
+type lruCache struct {
+	mu     sync.Mutex
+	size   int
+	ll     *list.List // nejnověji použité položky na začátku
+	items  map[string]*list.Element
+	hits   int
+	misses int
+}
+
+type lruEntry struct {
+	key    string
+	tokens []string
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// get vrátí uloženou segmentaci pre-tokenu. Vrácený slice se nesmí měnit.
+func (c *lruCache) get(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		c.hits++
+		return el.Value.(*lruEntry).tokens, true
+	}
+	c.misses++
+	return nil, false
+}
+
+// add uloží segmentaci pre-tokenu; při překročení kapacity vyhodí nejdéle nepoužitou.
+func (c *lruCache) add(key string, tokens []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*lruEntry).tokens = tokens
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, tokens: tokens})
+	if c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// stats vrátí počet zásahů a výpadků cache.
+func (c *lruCache) stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
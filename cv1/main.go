@@ -1,38 +1,322 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+
+	"github.com/ajrac/MATD/stopwords"
 )
 
+const demoText = `the cat sat on the mat the cat ate the rat and the bat sat on the flat hat ` +
+	`the cat sat on the mat again and the rat ran from the bat the cat and the rat sat together ` +
+	`on the mat while the bat flew over the flat hat the cat chased the rat around the mat and ` +
+	`the bat watched from the hat`
+
 func main() {
-	// path := "data.csv"
-	// if len(os.Args) > 1 {
-	// 	path = os.Args[1]
-	// }
-	// fmt.Println("Using path:", path)
+	var cfg TokenizerConfig
+	flag.StringVar(&cfg.Name, "tokenizer", "byte-bpe", "tokenizer: "+strings.Join(TokenizerNames(), ", "))
+	flag.IntVar(&cfg.K, "k", 1000, "počet merge operací")
+	flag.TextVar(&cfg.Options.Marker, "marker", EndOfWord, "značka hranic slov pro word-bpe: end_of_word, space_prefix")
+	flag.TextVar(&cfg.Options.Merges, "merges", AnyMerge, "omezení merge operací podle tříd znaků: any, separate, strict")
+	flag.StringVar(&cfg.Options.VocabFile, "vocab", "", "externí slovník pro max-match (jeden token na řádek)")
+	flag.TextVar(&cfg.Options.Dampening, "dampening", NoDampening, "tlumení četností pro morfessor: none, log, ones")
+	compareWith := flag.String("compare", "", "druhý tokenizer, jehož slovník se porovná s prvním (stejné K a parametry)")
+	csvPath := flag.String("csv", "", "soubor pro CSV výstup srovnání slovníků (s -compare)")
+	top := flag.Int("top", 20, "počet nejčetnějších slov ve statistikách (-1 = všechna)")
+	statsFormat := flag.String("stats-format", "text", "formát statistik korpusu: text, json, csv")
+	statsOut := flag.String("stats-out", "", "soubor pro statistiky korpusu (jinak standardní výstup; json a csv pak bez výstupu tokenizeru)")
+	zipfCSV := flag.String("zipf-csv", "", "soubor pro body Zipfova grafu (CSV)")
+	heapsCSV := flag.String("heaps-csv", "", "soubor pro body Heapsova grafu (CSV)")
+	learnAbbrev := flag.Bool("learn-abbrev", false, "naučit se zkratky z textu pro dělení na věty (Punkt)")
+	stopPath := flag.String("stopwords", "", "vlastní seznam stop slov (jedno na řádek), přidá se k vestavěnému českému a anglickému")
+	docsPath := flag.String("docs", "", "kolekce dokumentů (adresář nebo JSONL s polem text) pro klíčová slova TF-IDF")
+	var tfScheme TFScheme
+	var idfScheme IDFScheme
+	flag.TextVar(&tfScheme, "tf", RawTF, "váhování TF: raw, log, augmented, boolean")
+	flag.TextVar(&idfScheme, "idf", StandardIDF, "váhování IDF: standard, smooth, prob")
+	keynessRef := flag.String("keyness", "", "referenční korpus, se kterým se vstup porovná (klíčová slova podle keyness)")
+	var keynessBy KeynessMeasure
+	flag.TextVar(&keynessBy, "keyness-by", KeynessLL, "míra keyness pro řazení: ll, diff, ratio")
+	keynessP := flag.Float64("keyness-p", 0.05, "hladina významnosti G² pro keyness (0.05, 0.01, 0.001, 0.0001; 1 = vše)")
+	kwic := flag.String("kwic", "", "konkordance (KWIC): hledané slovo, fráze nebo s -kwic-regex regulární výraz")
+	var kwicOpts ConcordanceOptions
+	flag.BoolVar(&kwicOpts.Regex, "kwic-regex", false, "dotaz -kwic je regulární výraz")
+	flag.BoolVar(&kwicOpts.CaseSensitive, "kwic-case", false, "konkordance rozlišuje velikost písmen")
+	flag.IntVar(&kwicOpts.Width, "kwic-width", 40, "šířka levého a pravého kontextu ve znacích")
+	flag.TextVar(&kwicOpts.Sort, "kwic-sort", SortByPosition, "řazení konkordance: none, left, right")
+	flag.IntVar(&kwicOpts.Limit, "kwic-limit", 0, "nejvýše tolik řádků konkordance (0 = všechny)")
+	configPath := flag.String("config", "", "JSON konfigurace tokenizeru (nahradí ostatní přepínače)")
+	var countPaths []string
+	flag.Func("counts", "soubor s četnostmi slov (slovo<TAB>četnost nebo výpis statistik); lze opakovat", func(path string) error {
+		countPaths = append(countPaths, path)
+		return nil
+	})
+	var corpusFlags []string
+	flag.Func("corpus", "korpus jazyka jako jazyk=soubor s četnostmi slov pro společný slovník word-bpe; lze opakovat", func(v string) error {
+		if _, _, err := parseCorpusFlag(v); err != nil {
+			return err
+		}
+		corpusFlags = append(corpusFlags, v)
+		return nil
+	})
+	temperature := flag.Float64("temperature", 1, "teplota vzorkování korpusů (1 = poměr velikostí, vyšší vyrovnává jazyky)")
+	flag.Parse()
+
+	if *configPath != "" {
+		f, err := os.Open(*configPath)
+		if err != nil {
+			fmt.Println("Error reading config:", err)
+			return
+		}
+		cfg, err = ReadTokenizerConfig(f)
+		f.Close()
+		if err != nil {
+			fmt.Println("Error reading config:", err)
+			return
+		}
+	}
+
+	tok, err := cfg.New()
+	if err != nil {
+		fmt.Println("Error creating tokenizer:", err)
+		return
+	}
+
+	stop := stopwords.Default()
+	if *stopPath != "" {
+		extra, err := stopwords.Load(*stopPath)
+		if err != nil {
+			fmt.Println("Error reading stopwords:", err)
+			return
+		}
+		stop.Add(extra)
+	}
+
+	if *docsPath != "" {
+		printKeywords(*docsPath, Weighting{TF: tfScheme, IDF: idfScheme}, *top, stop)
+		return
+	}
+
+	if len(corpusFlags) > 0 {
+		trainMultilingual(tok, cfg, corpusFlags, *temperature)
+		return
+	}
+
+	if len(countPaths) > 0 {
+		trainFromCounts(tok, cfg, countPaths)
+		return
+	}
 
-	// data, err := os.ReadFile(path)
+	// Strukturované statistiky na standardním výstupu se nesmí míchat s dalšími
+	// výpisy, aby šly rovnou zpracovat.
+	statsOnly := *statsOut == "" && (*statsFormat == "json" || *statsFormat == "csv")
 
-	// if err != nil {
-	// 	fmt.Println("Error reading file:", err)
-	// 	return
-	// }
+	text := demoText
+	if flag.NArg() > 0 {
+		path := flag.Arg(0)
+		if !statsOnly {
+			fmt.Println("Using path:", path)
+		}
 
-	// fmt.Println("File size:", len(data), "bytes")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Println("Error reading file:", err)
+			return
+		}
+
+		if !statsOnly {
+			fmt.Println("File size:", len(data), "bytes")
+		}
+		text = string(data)
+	}
+
+	if *kwic != "" {
+		lines, err := Concordance(text, *kwic, kwicOpts)
+		if err != nil {
+			fmt.Println("Error searching concordance:", err)
+			return
+		}
+		fmt.Println("Počet výskytů:", len(lines))
+		WriteConcordance(os.Stdout, lines, kwicOpts.Width)
+		return
+	}
+
+	if *keynessRef != "" {
+		printKeyness(text, *keynessRef, keynessBy, *keynessP, *top)
+		return
+	}
+
+	splitter := NewSentenceSplitter()
+	if *learnAbbrev {
+		splitter.LearnAbbreviations(text, 2)
+	}
+	sentences := ComputeSentenceStats(text, splitter)
 
-	text := `the cat sat on the mat the cat ate the rat and the bat sat on the flat hat ` +
-		`the cat sat on the mat again and the rat ran from the bat the cat and the rat sat together ` +
-		`on the mat while the bat flew over the flat hat the cat chased the rat around the mat and ` +
-		`the bat watched from the hat`
 	text = clean(text)
-	printStatistics(text)
+	stats := ComputeStatistics(text, *top, stop)
+	stats.Sentences = &sentences
+	writeStats := func(w io.Writer) error { return stats.Write(w, *statsFormat) }
+	if *statsOut != "" {
+		writeFile(*statsOut, writeStats)
+	} else if err := writeStats(os.Stdout); err != nil {
+		fmt.Println("Error writing statistics:", err)
+		return
+	}
+	writeFile(*zipfCSV, stats.WriteZipfCSV)
+	writeFile(*heapsCSV, stats.WriteHeapsCSV)
+	if statsOnly {
+		return
+	}
+
+	res := tok.Train(text, cfg.K)
+	printResult(cfg.Name, res)
+
+	if *compareWith != "" {
+		compareTokenizers(cfg, res, *compareWith, text, *csvPath)
+	}
+}
+
+// compareTokenizers naučí druhý tokenizer na stejném textu a porovná slovníky.
+func compareTokenizers(cfg TokenizerConfig, res *Result, other, text, csvPath string) {
+	tok, err := NewTokenizer(other, cfg.Options)
+	if err != nil {
+		fmt.Println("Error creating tokenizer:", err)
+		return
+	}
+	cmp := CompareVocabs(cfg.Name, res, other, tok.Train(text, cfg.K))
+	cmp.Print(os.Stdout, 20)
 
-	tok := ByteTokenizer{}
-	vocab, sequence := tok.Tokenize(text, 1000)
-	fmt.Println("Vocab size:", len(vocab))
-	fmt.Println("Sequence length:", len(sequence))
+	writeFile(csvPath, cmp.WriteCSV)
+}
+
+// writeFile zapíše výstup do souboru na dané cestě; prázdná cesta nic nezapíše.
+func writeFile(path string, write func(io.Writer) error) {
+	if path == "" {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Println("Error writing file:", err)
+		return
+	}
+	if err := write(f); err != nil {
+		fmt.Println("Error writing file:", err)
+	}
+	if err := f.Close(); err != nil {
+		fmt.Println("Error writing file:", err)
+	}
+}
+
+// trainFromCounts naučí tokenizer ze sečtených tabulek četností slov.
+func trainFromCounts(tok Tokenizer, cfg TokenizerConfig, paths []string) {
+	ct, ok := tok.(CountsTrainer)
+	if !ok {
+		fmt.Println("Tokenizer", cfg.Name, "neumí učení z četností slov")
+		return
+	}
+
+	counts, err := loadWordCounts(paths...)
+	if err != nil {
+		fmt.Println("Error reading counts:", err)
+		return
+	}
+	fmt.Println("Počet unikátních slov:", len(counts))
+
+	printResult(cfg.Name, ct.TrainCounts(counts, cfg.K))
+}
+
+// trainMultilingual naučí společný slovník na korpusech více jazyků.
+func trainMultilingual(tok Tokenizer, cfg TokenizerConfig, corpusFlags []string, temperature float64) {
+	wt, ok := tok.(WordTokenizer)
+	if !ok {
+		fmt.Println("Společné učení více jazyků umí jen word-bpe, ne", cfg.Name)
+		return
+	}
+
+	var corpora []Corpus
+	for _, v := range corpusFlags {
+		lang, path, _ := parseCorpusFlag(v)
+		counts, err := loadWordCounts(path)
+		if err != nil {
+			fmt.Println("Error reading counts:", err)
+			return
+		}
+		corpora = append(corpora, Corpus{Lang: lang, Counts: counts})
+	}
+
+	res, stats, err := wt.TrainMultilingual(corpora, cfg.K, temperature)
+	if err != nil {
+		fmt.Println("Error encoding corpus:", err)
+		return
+	}
+	printResult(cfg.Name, res)
+	PrintLanguageStats(os.Stdout, stats)
+}
+
+// printKeywords vypíše klíčová slova TF-IDF každého dokumentu kolekce.
+func printKeywords(path string, w Weighting, top int, stop stopwords.List) {
+	docs, err := LoadDocuments(path)
+	if err != nil {
+		fmt.Println("Error reading documents:", err)
+		return
+	}
+	c := NewCollection(docs, stop)
+	fmt.Println("Počet dokumentů:", len(docs))
+	fmt.Println("Počet termů:", len(c.DF))
+	for i, d := range docs {
+		fmt.Printf("%s (TF %s, IDF %s):\n", d.Name, w.TF, w.IDF)
+		for j, kw := range c.Keywords(i, w, top) {
+			fmt.Printf("%2d. %q — %.4f (tf %d, df %d)\n", j+1, kw.Term, kw.Score, kw.TF, kw.DF)
+		}
+	}
+}
+
+// printKeyness vypíše slova typická pro vstupní text a pro referenční korpus.
+func printKeyness(text, refPath string, by KeynessMeasure, p float64, top int) {
+	ref, err := os.ReadFile(refPath)
+	if err != nil {
+		fmt.Println("Error reading file:", err)
+		return
+	}
+	kws, err := Keyness(wordFrequencies(strings.Fields(clean(text))), wordFrequencies(strings.Fields(clean(string(ref)))), by, p)
+	if err != nil {
+		fmt.Println("Error computing keyness:", err)
+		return
+	}
+	if top < 0 || top > len(kws) {
+		top = len(kws)
+	}
+
+	row := func(k KeyWord) {
+		fmt.Printf("%-20q %6d %6d %10.2f%-4s %12.4g %8.2f\n", k.Word, k.FreqA, k.FreqB, k.LL, k.significance(), k.PercentDiff, k.LogRatio)
+	}
+	header := fmt.Sprintf("%-20s %6s %6s %14s %12s %8s", "Slovo", "Vstup", "Ref.", "G²", "%DIFF", "Log ratio")
+	fmt.Printf("Typická pro vstup (řazeno podle %s, p ≤ %g):\n%s\n", by, p, header)
+	for _, k := range kws[:top] {
+		if k.score(by) <= 0 {
+			break
+		}
+		row(k)
+	}
+	fmt.Printf("Typická pro %s:\n%s\n", refPath, header)
+	for i := len(kws) - 1; i >= len(kws)-top; i-- {
+		if kws[i].score(by) >= 0 {
+			break
+		}
+		row(kws[i])
+	}
+}
 
+func printResult(name string, res *Result) {
+	fmt.Println("Tokenizer:", name)
+	fmt.Println("Vocab size:", len(res.Vocab))
+	fmt.Println("Merges:", res.Stats.Merges)
+	fmt.Println("Words:", res.Stats.Words)
+	fmt.Println("Sequence length:", len(res.Tokens))
+	fmt.Println("Training time:", res.Stats.Duration)
 }
 
 func clean(text string) string {
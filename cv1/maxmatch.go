@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// MaxMatchTokenizer segmentuje slova hladovým nejdelším shodným prefixem
+// (MaxMatch) podle pevného slovníku uloženého v trii. Slovník může být externí
+// seznam slov, nebo se naučí tokenizerem Base na stejném textu s k merge
+// operacemi. Značky hranic slov se ze slovníku odstraní; znak, který ve slovníku
+// není, tvoří samostatný token.
+//
+// Hranice slov se vyznačí stejně jako u WordTokenizer: v režimu EndOfWord nese
+// poslední token slova značku <end_of_word>, v režimu SpacePrefix začíná první
+// token slova za mezerou znakem ▁.
+type MaxMatchTokenizer struct {
+	// Vocab je pevný slovník. Je-li prázdný, Train se slovník naučí pomocí Base.
+	Vocab []string
+	// Base je tokenizer pro naučení slovníku (nil = WordTokenizer se stejnou značkou).
+	Base   Tokenizer
+	Marker WordMarker
+}
+
+// trieNode je uzel trie nad znaky slovníku.
+type trieNode struct {
+	children map[rune]*trieNode
+	terminal bool
+}
+
+func newTrie(vocab []string) *trieNode {
+	root := &trieNode{}
+	for _, tok := range vocab {
+		tok = strings.TrimSpace(stripMarkers(tok))
+		if tok == "" {
+			continue
+		}
+		n := root
+		for _, r := range tok {
+			child, ok := n.children[r]
+			if !ok {
+				if n.children == nil {
+					n.children = make(map[rune]*trieNode)
+				}
+				child = &trieNode{}
+				n.children[r] = child
+			}
+			n = child
+		}
+		n.terminal = true
+	}
+	return root
+}
+
+// longestMatch vrátí délku nejdelšího tokenu slovníku, kterým začíná word[i:],
+// nebo 0, když žádný není.
+func (root *trieNode) longestMatch(word []rune, i int) int {
+	best := 0
+	n := root
+	for j := i; j < len(word); j++ {
+		n = n.children[word[j]]
+		if n == nil {
+			break
+		}
+		if n.terminal {
+			best = j - i + 1
+		}
+	}
+	return best
+}
+
+// segment rozdělí slovo nejdelšími shodami zleva doprava.
+func (root *trieNode) segment(w string) []string {
+	word := []rune(w)
+	var seg []string
+	for i := 0; i < len(word); {
+		n := max(root.longestMatch(word, i), 1)
+		seg = append(seg, string(word[i:i+n]))
+		i += n
+	}
+	return seg
+}
+
+func (t MaxMatchTokenizer) Tokenize(text string, k int) ([]string, []string) {
+	return t.Train(text, k).Legacy()
+}
+
+// Train segmentuje slova textu. S pevným slovníkem se k nepoužívá. Slovník
+// výsledku tvoří tokeny použité v sekvenci, ID jsou v abecedním pořadí.
+func (t MaxMatchTokenizer) Train(text string, k int) *Result {
+	start := time.Now()
+	vocab := t.Vocab
+	if len(vocab) == 0 {
+		base := t.Base
+		if base == nil {
+			base = WordTokenizer{Marker: t.Marker}
+		}
+		vocab = base.Train(text, k).Ranks().Tokens()
+	}
+	trie := newTrie(vocab)
+
+	fields := WordTokenizer{Marker: t.Marker}.words(text)
+	used := make(map[string]struct{})
+	segCache := make(map[string][]string)
+	var tokens []Token
+	for i, w := range fields {
+		seg, ok := segCache[w]
+		if !ok {
+			seg = t.segment(trie, w)
+			segCache[w] = seg
+		}
+		for _, s := range seg {
+			used[s] = struct{}{}
+			tokens = append(tokens, Token{Text: s, Word: i})
+		}
+	}
+
+	return newResult(sortedRanks(used), nil, tokens, textStats(text, len(fields), 0, start))
+}
+
+// segment rozdělí slovo podle trie a vyznačí hranici slova.
+func (t MaxMatchTokenizer) segment(trie *trieNode, w string) []string {
+	if t.Marker != SpacePrefix {
+		seg := trie.segment(w)
+		seg[len(seg)-1] += "<end_of_word>"
+		return seg
+	}
+	core := strings.TrimPrefix(w, spaceMarker)
+	seg := trie.segment(core)
+	if len(seg) == 0 {
+		seg = []string{""}
+	}
+	seg[0] = w[:len(w)-len(core)] + seg[0]
+	return seg
+}
+
+// ReadVocabList načte externí slovník: jeden token na řádek, prázdné řádky
+// a řádky začínající # se přeskočí. Za tabulátorem může následovat další
+// sloupec (např. četnost), ten se ignoruje.
+func ReadVocabList(r io.Reader) ([]string, error) {
+	var vocab []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tok, _, _ := strings.Cut(line, "\t")
+		vocab = append(vocab, tok)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return vocab, nil
+}
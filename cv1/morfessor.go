@@ -0,0 +1,321 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MorfessorTokenizer je nesupervizovaný morfologický segmentér podle Morfessor
+// Baseline (Creutz & Lagus). Místo četnosti párů minimalizuje délku popisu
+// (MDL): cenu lexikonu morfů plus cenu korpusu zakódovaného těmito morfy.
+// Každé slovo se rekurzivně dělí na dvě části, pokud to celkovou cenu sníží.
+//
+// Parametr k z rozhraní Tokenizer se nepoužívá – velikost lexikonu určuje
+// samo MDL kritérium.
+type MorfessorTokenizer struct {
+	// Dampening tlumí četnosti slov před učením.
+	Dampening Dampening
+	// MaxEpochs omezuje počet průchodů přes slovník (0 = 10).
+	MaxEpochs int
+	// Threshold ukončí učení, když cena za epochu klesne méně než o Threshold
+	// na slovo (0 = 0.005).
+	Threshold float64
+	// Seed určuje pořadí slov v jednotlivých epochách.
+	Seed int64
+}
+
+// Dampening určuje, jak se četnosti slov tlumí před učením. Bez tlumení
+// zůstávají častá slova celá, protože jejich dělení příliš zdraží korpus.
+type Dampening int
+
+const (
+	// NoDampening použije skutečné četnosti slov.
+	NoDampening Dampening = iota
+	// LogDampening použije 1 + ln(četnost).
+	LogDampening
+	// OnesDampening počítá každé slovo jednou (učení na typech).
+	OnesDampening
+)
+
+// apply vrátí utlumenou četnost slova (vždy alespoň 1).
+func (d Dampening) apply(c int) int {
+	switch d {
+	case LogDampening:
+		return 1 + int(math.Round(math.Log(float64(c))))
+	case OnesDampening:
+		return 1
+	}
+	return c
+}
+
+func (t MorfessorTokenizer) Tokenize(text string, k int) ([]string, []string) {
+	return t.Train(text, k).Legacy()
+}
+
+// Train naučí model na slovech textu. Slovník tvoří morfy použité v sekvenci,
+// ID jsou přidělena v abecedním pořadí.
+func (t MorfessorTokenizer) Train(text string, k int) *Result {
+	start := time.Now()
+	fields := strings.Fields(text)
+	m := t.train(wordFrequencies(fields))
+
+	// sekvence v pořadí původního textu; poslední morf slova nese značku konce slova
+	vocab := make(map[string]struct{})
+	segCache := make(map[string][]string)
+	var tokens []Token
+	for i, w := range fields {
+		seg, ok := segCache[w]
+		if !ok {
+			seg = m.segment(w)
+			seg[len(seg)-1] += "<end_of_word>"
+			segCache[w] = seg
+		}
+		for _, s := range seg {
+			vocab[s] = struct{}{}
+			tokens = append(tokens, Token{Text: s, Word: i})
+		}
+	}
+
+	return newResult(sortedRanks(vocab), nil, tokens, textStats(text, len(fields), len(m.charCounts), start))
+}
+
+// TrainCounts naučí model přímo z tabulky četností slov. Výsledek nemá
+// sekvenci tokenů; četnosti morfů jsou vážené četnostmi slov.
+func (t MorfessorTokenizer) TrainCounts(counts map[string]int, k int) *Result {
+	start := time.Now()
+	m := t.train(counts)
+
+	vocab := make(map[string]struct{})
+	segs := make(map[string][]string, len(counts))
+	for w := range counts {
+		seg := m.segment(w)
+		seg[len(seg)-1] += "<end_of_word>"
+		segs[w] = seg
+		for _, s := range seg {
+			vocab[s] = struct{}{}
+		}
+	}
+	return newCountsResult(sortedRanks(vocab), nil, segs, counts, len(m.charCounts), start)
+}
+
+// sortedRanks přidělí tokenům ID v abecedním pořadí.
+func sortedRanks(vocab map[string]struct{}) Ranks {
+	vocabList := make([]string, 0, len(vocab))
+	for s := range vocab {
+		vocabList = append(vocabList, s)
+	}
+	sort.Strings(vocabList)
+	ranks := make(Ranks, len(vocabList))
+	for i, s := range vocabList {
+		ranks[s] = i
+	}
+	return ranks
+}
+
+// train naučí model na četnostech slov.
+func (t MorfessorTokenizer) train(freq map[string]int) *morfModel {
+	epochs := t.MaxEpochs
+	if epochs <= 0 {
+		epochs = 10
+	}
+	threshold := t.Threshold
+	if threshold <= 0 {
+		threshold = 0.005
+	}
+
+	m := newMorfModel()
+	words := make([]string, 0, len(freq))
+	for w, c := range freq {
+		c = t.Dampening.apply(c)
+		words = append(words, w)
+		m.boundaries += c
+		m.modify(w, c)
+	}
+	sort.Strings(words) // deterministické pořadí před mícháním
+
+	rng := rand.New(rand.NewSource(t.Seed))
+	cost := m.cost()
+	for e := 0; e < epochs; e++ {
+		rng.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+		for _, w := range words {
+			m.recursiveSplit(w)
+		}
+		newCost := m.cost()
+		if cost-newCost < threshold*float64(len(words)) {
+			break
+		}
+		cost = newCost
+	}
+	return m
+}
+
+// morfNode je uzel stromu analýz. Pokud split > 0, uzel je rozdělen na dvě
+// části v daném bytovém offsetu a sám v lexikonu není; jinak je to morf.
+type morfNode struct {
+	count int
+	split int
+}
+
+// morfModel drží analýzy všech konstrukcí a průběžně počítané složky ceny,
+// aby se cena dala vyhodnotit v konstantním čase.
+type morfModel struct {
+	analyses map[string]morfNode
+
+	// korpus: výskyty morfů a počet slov (hranic)
+	tokens      int
+	boundaries  int
+	logTokenSum float64 // Σ f·log f přes morfy
+
+	// lexikon: znaky morfů, počet morfů a četnosti znaků
+	lexChars   int
+	types      int
+	charCounts map[rune]int
+	logCharSum float64 // Σ c·log c přes znaky
+}
+
+func newMorfModel() *morfModel {
+	return &morfModel{
+		analyses:   make(map[string]morfNode),
+		charCounts: make(map[rune]int),
+	}
+}
+
+// modify změní četnost konstrukce o dcount. U rozdělené konstrukce se změna
+// propaguje do obou částí, u morfu se aktualizuje korpus a případně lexikon.
+func (m *morfModel) modify(constr string, dcount int) {
+	node := m.analyses[constr]
+	newCount := node.count + dcount
+	if newCount == 0 {
+		delete(m.analyses, constr)
+	} else {
+		m.analyses[constr] = morfNode{count: newCount, split: node.split}
+	}
+
+	if node.split > 0 {
+		m.modify(constr[:node.split], dcount)
+		m.modify(constr[node.split:], dcount)
+		return
+	}
+
+	m.tokens += dcount
+	m.logTokenSum += xlogx(newCount) - xlogx(node.count)
+	if node.count == 0 && newCount > 0 {
+		m.updateLexicon(constr, 1)
+	} else if node.count > 0 && newCount == 0 {
+		m.updateLexicon(constr, -1)
+	}
+}
+
+// updateLexicon přidá (d = 1) nebo odebere (d = -1) morf z lexikonu.
+func (m *morfModel) updateLexicon(morph string, d int) {
+	m.types += d
+	for _, r := range morph {
+		c := m.charCounts[r]
+		m.charCounts[r] = c + d
+		m.logCharSum += xlogx(c+d) - xlogx(c)
+		m.lexChars += d
+	}
+}
+
+// cost vrátí celkovou délku popisu (v natech): cenu korpusu a cenu lexikonu.
+func (m *morfModel) cost() float64 {
+	if m.boundaries == 0 {
+		return 0
+	}
+
+	// Korpus: každý výskyt morfu a každý konec slova kódovaný podle své četnosti
+	n := float64(m.tokens + m.boundaries)
+	b := float64(m.boundaries)
+	corpus := n*math.Log(n) - b*math.Log(b) - m.logTokenSum
+
+	// Lexikon: řetězce morfů (znaky + konec morfu), jejich pořadí je libovolné,
+	// a rozdělení četností mezi morfy
+	var lexicon float64
+	if m.types > 0 {
+		ln := float64(m.lexChars + m.types)
+		lt := float64(m.types)
+		lexicon = ln*math.Log(ln) - lt*math.Log(lt) - m.logCharSum - logFactorial(m.types)
+		if m.types > 1 {
+			lexicon += logFactorial(m.tokens-1) - logFactorial(m.types-1) - logFactorial(m.tokens-m.types)
+		}
+	}
+	return corpus + lexicon
+}
+
+// recursiveSplit najde nejlevnější analýzu konstrukce: buď ji ponechá celou,
+// nebo ji rozdělí na dvě části a rekurzivně pokračuje v obou. Vrátí morfy.
+func (m *morfModel) recursiveSplit(constr string) []string {
+	if utf8.RuneCountInString(constr) == 1 {
+		return []string{constr}
+	}
+
+	count := m.analyses[constr].count
+	m.modify(constr, -count)
+
+	// Bez dělení
+	m.modify(constr, count)
+	best := m.cost()
+	m.modify(constr, -count)
+
+	bestSplit := 0
+	for i := range constr {
+		if i == 0 {
+			continue
+		}
+		prefix, suffix := constr[:i], constr[i:]
+		m.modify(prefix, count)
+		m.modify(suffix, count)
+		c := m.cost()
+		m.modify(prefix, -count)
+		m.modify(suffix, -count)
+		if c <= best {
+			best = c
+			bestSplit = i
+		}
+	}
+
+	if bestSplit == 0 {
+		m.modify(constr, count)
+		return []string{constr}
+	}
+
+	m.analyses[constr] = morfNode{count: count, split: bestSplit}
+	prefix, suffix := constr[:bestSplit], constr[bestSplit:]
+	m.modify(prefix, count)
+	m.modify(suffix, count)
+
+	left := m.recursiveSplit(prefix)
+	if suffix == prefix {
+		return append(left, left...)
+	}
+	return append(left, m.recursiveSplit(suffix)...)
+}
+
+// segment vrátí morfy slova podle naučených analýz. Neznámé slovo zůstane celé.
+func (m *morfModel) segment(w string) []string {
+	node, ok := m.analyses[w]
+	if !ok || node.split == 0 {
+		return []string{w}
+	}
+	return append(m.segment(w[:node.split]), m.segment(w[node.split:])...)
+}
+
+func xlogx(x int) float64 {
+	if x <= 0 {
+		return 0
+	}
+	f := float64(x)
+	return f * math.Log(f)
+}
+
+func logFactorial(n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	lg, _ := math.Lgamma(float64(n) + 1)
+	return lg
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Corpus je tabulka četností slov jednoho jazyka pro společné učení slovníku.
+type Corpus struct {
+	Lang   string
+	Counts map[string]int
+}
+
+// LanguageStats jsou údaje o jednom jazyce po společném učení. Fertilita je
+// průměrný počet tokenů na slovo; vyšší fertilita znamená, že jazyk je ve
+// slovníku zastoupen hůř.
+type LanguageStats struct {
+	Lang      string
+	Words     int     // počet slov korpusu (součet četností)
+	Share     float64 // podíl jazyka v korpusu před vzorkováním
+	Weight    float64 // podíl jazyka po vzorkování
+	Tokens    int
+	Fertility float64
+}
+
+// SamplingWeights vrátí podíly korpusů po vzorkování s teplotou:
+// p_i ∝ (n_i / N)^(1/T). T = 1 zachová poměr velikostí, vyšší teplota
+// vyrovnává malé jazyky s velkými. Teplota <= 0 se bere jako 1.
+func SamplingWeights(corpora []Corpus, temperature float64) []float64 {
+	if temperature <= 0 {
+		temperature = 1
+	}
+	sizes := make([]float64, len(corpora))
+	var total float64
+	for i, c := range corpora {
+		sizes[i] = float64(countTotal(c.Counts))
+		total += sizes[i]
+	}
+
+	weights := make([]float64, len(corpora))
+	var sum float64
+	for i, n := range sizes {
+		if n > 0 {
+			weights[i] = math.Pow(n/total, 1/temperature)
+			sum += weights[i]
+		}
+	}
+	for i := range weights {
+		if sum > 0 {
+			weights[i] /= sum
+		}
+	}
+	return weights
+}
+
+// MixCounts sečte korpusy do jedné tabulky četností. Četnosti každého korpusu se
+// přeškálují tak, aby jeho podíl odpovídal SamplingWeights a celkový počet slov
+// zůstal zachován; slovo s nenulovou četností si ponechá aspoň 1.
+func MixCounts(corpora []Corpus, temperature float64) map[string]int {
+	weights := SamplingWeights(corpora, temperature)
+	var total int
+	for _, c := range corpora {
+		total += countTotal(c.Counts)
+	}
+
+	mixed := make(map[string]int)
+	for i, c := range corpora {
+		n := countTotal(c.Counts)
+		if n == 0 {
+			continue
+		}
+		scale := weights[i] * float64(total) / float64(n)
+		for w, cnt := range c.Counts {
+			mixed[w] += max(1, int(math.Round(float64(cnt)*scale)))
+		}
+	}
+	return mixed
+}
+
+// TrainMultilingual naučí společný slovník na korpusech více jazyků vzorkovaných
+// s danou teplotou a spočítá fertilitu každého jazyka naučeným slovníkem.
+func (t WordTokenizer) TrainMultilingual(corpora []Corpus, k int, temperature float64) (*Result, []LanguageStats, error) {
+	res := t.TrainCounts(MixCounts(corpora, temperature), k)
+
+	enc := t.Encoder(res.Ranks())
+	weights := SamplingWeights(corpora, temperature)
+	var total int
+	for _, c := range corpora {
+		total += countTotal(c.Counts)
+	}
+
+	stats := make([]LanguageStats, len(corpora))
+	for i, c := range corpora {
+		s := LanguageStats{Lang: c.Lang, Words: countTotal(c.Counts), Weight: weights[i]}
+		if total > 0 {
+			s.Share = float64(s.Words) / float64(total)
+		}
+		for w, cnt := range c.Counts {
+			// mezera vpředu, aby slovo dostalo v režimu SpacePrefix značku ▁ jako při učení
+			seq, err := enc.Encode(" " + w)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", c.Lang, err)
+			}
+			s.Tokens += cnt * len(seq)
+		}
+		if s.Words > 0 {
+			s.Fertility = float64(s.Tokens) / float64(s.Words)
+		}
+		stats[i] = s
+	}
+	return res, stats, nil
+}
+
+// PrintLanguageStats vypíše fertilitu jazyků seřazenou podle jména jazyka.
+func PrintLanguageStats(w io.Writer, stats []LanguageStats) {
+	sorted := append([]LanguageStats(nil), stats...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Lang < sorted[j].Lang })
+
+	fmt.Fprintf(w, "%-6s %10s %8s %8s %10s %10s\n", "Jazyk", "Slova", "Podíl", "Váha", "Tokeny", "Fertilita")
+	for _, s := range sorted {
+		fmt.Fprintf(w, "%-6s %10d %7.1f%% %7.1f%% %10d %10.3f\n",
+			s.Lang, s.Words, s.Share*100, s.Weight*100, s.Tokens, s.Fertility)
+	}
+}
+
+// parseCorpusFlag rozloží hodnotu přepínače -corpus ve tvaru jazyk=cesta.
+func parseCorpusFlag(v string) (lang, path string, err error) {
+	lang, path, ok := strings.Cut(v, "=")
+	if !ok || lang == "" || path == "" {
+		return "", "", fmt.Errorf("očekáváno jazyk=cesta, dostáno %q", v)
+	}
+	return lang, path, nil
+}
+
+func countTotal(counts map[string]int) int {
+	var n int
+	for _, c := range counts {
+		n += c
+	}
+	return n
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TokenizerOptions jsou parametry tokenizerů. Každý tokenizer použije jen ty,
+// které se ho týkají; nulové hodnoty znamenají výchozí nastavení.
+type TokenizerOptions struct {
+	// Marker je značka hranic slov pro word-bpe.
+	Marker WordMarker `json:"marker"`
+	// Merges omezuje merge operace word-bpe a byte-bpe podle tříd znaků.
+	Merges MergePolicy `json:"merges"`
+	// VocabFile je externí slovník pro max-match (jeden token na řádek); bez něj
+	// se slovník naučí word-bpe s parametry Marker a Merges.
+	VocabFile string `json:"vocab_file"`
+	// Dampening, MaxEpochs, Threshold a Seed jsou parametry morfessoru.
+	Dampening Dampening `json:"dampening"`
+	MaxEpochs int       `json:"max_epochs"`
+	Threshold float64   `json:"threshold"`
+	Seed      int64     `json:"seed"`
+}
+
+// TokenizerConfig vybírá tokenizer podle jména, např. z konfiguračního souboru:
+//
+//	{"tokenizer": "word-bpe", "k": 1000, "options": {"marker": "space_prefix"}}
+type TokenizerConfig struct {
+	Name    string           `json:"tokenizer"`
+	K       int              `json:"k"`
+	Options TokenizerOptions `json:"options"`
+}
+
+// TokenizerFactory vytvoří tokenizer s danými parametry.
+type TokenizerFactory func(opts TokenizerOptions) (Tokenizer, error)
+
+var tokenizerRegistry = make(map[string]TokenizerFactory)
+
+func init() {
+	RegisterTokenizer("word-bpe", func(opts TokenizerOptions) (Tokenizer, error) {
+		return WordTokenizer{Marker: opts.Marker, Allow: opts.Merges.Constraint()}, nil
+	})
+	RegisterTokenizer("byte-bpe", func(opts TokenizerOptions) (Tokenizer, error) {
+		return ByteTokenizer{Allow: opts.Merges.Constraint()}, nil
+	})
+	RegisterTokenizer("max-match", func(opts TokenizerOptions) (Tokenizer, error) {
+		t := MaxMatchTokenizer{Marker: opts.Marker, Base: WordTokenizer{Marker: opts.Marker, Allow: opts.Merges.Constraint()}}
+		if opts.VocabFile != "" {
+			f, err := os.Open(opts.VocabFile)
+			if err != nil {
+				return nil, fmt.Errorf("max-match: %w", err)
+			}
+			defer f.Close()
+			if t.Vocab, err = ReadVocabList(f); err != nil {
+				return nil, fmt.Errorf("max-match: %s: %w", opts.VocabFile, err)
+			}
+		}
+		return t, nil
+	})
+	RegisterTokenizer("morfessor", func(opts TokenizerOptions) (Tokenizer, error) {
+		if opts.MaxEpochs < 0 || opts.Threshold < 0 {
+			return nil, fmt.Errorf("morfessor: max_epochs a threshold nesmí být záporné")
+		}
+		return MorfessorTokenizer{
+			Dampening: opts.Dampening,
+			MaxEpochs: opts.MaxEpochs,
+			Threshold: opts.Threshold,
+			Seed:      opts.Seed,
+		}, nil
+	})
+}
+
+// RegisterTokenizer zaregistruje tokenizer pod daným jménem. Dvojí registrace
+// stejného jména je chyba programu, proto panikaří.
+func RegisterTokenizer(name string, f TokenizerFactory) {
+	if _, dup := tokenizerRegistry[name]; dup {
+		panic("tokenizer " + name + " je už zaregistrován")
+	}
+	tokenizerRegistry[name] = f
+}
+
+// NewTokenizer vytvoří zaregistrovaný tokenizer podle jména.
+func NewTokenizer(name string, opts TokenizerOptions) (Tokenizer, error) {
+	f, ok := tokenizerRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("neznámý tokenizer %q (dostupné: %s)", name, strings.Join(TokenizerNames(), ", "))
+	}
+	return f(opts)
+}
+
+// TokenizerNames vrátí seřazená jména zaregistrovaných tokenizerů.
+func TokenizerNames() []string {
+	names := make([]string, 0, len(tokenizerRegistry))
+	for name := range tokenizerRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadTokenizerConfig načte konfiguraci tokenizeru ve formátu JSON.
+// Neznámé klíče jsou chyba, aby se překlep v názvu parametru neztratil.
+func ReadTokenizerConfig(r io.Reader) (TokenizerConfig, error) {
+	var c TokenizerConfig
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return TokenizerConfig{}, fmt.Errorf("konfigurace tokenizeru: %w", err)
+	}
+	return c, nil
+}
+
+// New vytvoří tokenizer podle konfigurace. Tokenizery, které se učí merge
+// operace, potřebují kladné K.
+func (c TokenizerConfig) New() (Tokenizer, error) {
+	t, err := NewTokenizer(c.Name, c.Options)
+	if err != nil {
+		return nil, err
+	}
+	if c.needsK() && c.K <= 0 {
+		return nil, fmt.Errorf("konfigurace tokenizeru: %s potřebuje kladné k, je %d", c.Name, c.K)
+	}
+	return t, nil
+}
+
+// needsK hlásí, zda tokenizer používá K: BPE vždy, max-match jen bez
+// externího slovníku, kdy se slovník učí pomocí BPE. Morfessor K nepoužívá.
+func (c TokenizerConfig) needsK() bool {
+	switch c.Name {
+	case "word-bpe", "byte-bpe":
+		return true
+	case "max-match":
+		return c.Options.VocabFile == ""
+	}
+	return false
+}
+
+func (m WordMarker) String() string {
+	switch m {
+	case EndOfWord:
+		return "end_of_word"
+	case SpacePrefix:
+		return "space_prefix"
+	}
+	return fmt.Sprintf("WordMarker(%d)", int(m))
+}
+
+func (m WordMarker) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *WordMarker) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "end_of_word", "":
+		*m = EndOfWord
+	case "space_prefix":
+		*m = SpacePrefix
+	default:
+		return fmt.Errorf("neznámá značka hranic slov %q (end_of_word, space_prefix)", text)
+	}
+	return nil
+}
+
+func (d Dampening) String() string {
+	switch d {
+	case NoDampening:
+		return "none"
+	case LogDampening:
+		return "log"
+	case OnesDampening:
+		return "ones"
+	}
+	return fmt.Sprintf("Dampening(%d)", int(d))
+}
+
+func (d Dampening) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Dampening) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "none", "":
+		*d = NoDampening
+	case "log":
+		*d = LogDampening
+	case "ones":
+		*d = OnesDampening
+	default:
+		return fmt.Errorf("neznámé tlumení %q (none, log, ones)", text)
+	}
+	return nil
+}
+
+func (p MergePolicy) String() string {
+	switch p {
+	case AnyMerge:
+		return "any"
+	case SeparateDigitsPunct:
+		return "separate"
+	case StrictClasses:
+		return "strict"
+	}
+	return fmt.Sprintf("MergePolicy(%d)", int(p))
+}
+
+func (p MergePolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *MergePolicy) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "any", "":
+		*p = AnyMerge
+	case "separate":
+		*p = SeparateDigitsPunct
+	case "strict":
+		*p = StrictClasses
+	default:
+		return fmt.Errorf("neznámá politika merge operací %q (any, separate, strict)", text)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"sort"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Result je výsledek učení tokenizeru na textu.
+type Result struct {
+	// Vocab je celý naučený slovník seřazený podle ID. U BPE je ID rank tokenu,
+	// takže slovník obsahuje i mezivýsledky merge operací, které v sekvenci
+	// nezůstaly (mají Freq 0).
+	Vocab []VocabEntry
+	// Merges jsou merge operace v pořadí učení (Morfessor žádné nemá).
+	Merges []Merge
+	// Tokens je tokenizovaný text v původním pořadí.
+	Tokens []Token
+	Stats  TrainStats
+}
+
+// VocabEntry je token slovníku s jeho ID a četností v tokenizovaném textu.
+type VocabEntry struct {
+	ID    int
+	Token string
+	Freq  int
+}
+
+// Token je jeden token sekvence. Word je index slova textu, ke kterému token
+// patří; u ByteBPE slovo, ve kterém token začíná (mezera patří k předchozímu slovu).
+type Token struct {
+	ID   int
+	Text string
+	Word int
+}
+
+// TrainStats jsou souhrnné údaje o učení.
+type TrainStats struct {
+	Words    int // počet slov (pre-tokenů) v textu
+	Chars    int // počet znaků textu
+	Alphabet int // velikost počáteční abecedy
+	Merges   int // počet provedených merge operací
+	Duration time.Duration
+}
+
+// Tokenizer se naučí slovník na textu s nejvýše k merge operacemi a vrátí
+// tokenizovaný text i se slovníkem.
+type Tokenizer interface {
+	Train(text string, k int) *Result
+}
+
+// CountsTrainer je tokenizer, který se umí učit přímo z tabulky četností slov.
+type CountsTrainer interface {
+	TrainCounts(counts map[string]int, k int) *Result
+}
+
+// LegacyTokenizer je původní rozhraní tokenizeru: neseřazený slovník tokenů
+// použitých v sekvenci a samotná sekvence.
+type LegacyTokenizer interface {
+	Tokenize(text string, k int) ([]string, []string)
+}
+
+// Legacy přizpůsobí Tokenizer původnímu rozhraní.
+func Legacy(t Tokenizer) LegacyTokenizer {
+	return legacyTokenizer{t}
+}
+
+type legacyTokenizer struct {
+	t Tokenizer
+}
+
+func (l legacyTokenizer) Tokenize(text string, k int) ([]string, []string) {
+	return l.t.Train(text, k).Legacy()
+}
+
+// Legacy vrátí výsledek v původním tvaru: tokeny slovníku, které se
+// v sekvenci vyskytují, a sekvenci tokenů.
+func (r *Result) Legacy() ([]string, []string) {
+	vocab := make([]string, 0, len(r.Vocab))
+	for _, v := range r.Vocab {
+		if v.Freq > 0 {
+			vocab = append(vocab, v.Token)
+		}
+	}
+	return vocab, r.Sequence()
+}
+
+// Sequence vrátí texty tokenů v pořadí.
+func (r *Result) Sequence() []string {
+	seq := make([]string, len(r.Tokens))
+	for i, tok := range r.Tokens {
+		seq[i] = tok.Text
+	}
+	return seq
+}
+
+// Ranks vrátí slovník jako ranky pro Encoder a zápis do .tiktoken.
+func (r *Result) Ranks() Ranks {
+	ranks := make(Ranks, len(r.Vocab))
+	for _, v := range r.Vocab {
+		ranks[v.Token] = v.ID
+	}
+	return ranks
+}
+
+// newResult sestaví výsledek z ranků a sekvence; četnosti tokenů se spočítají
+// ze sekvence a ID tokenů v sekvenci se doplní z ranků.
+func newResult(ranks Ranks, merges []Merge, tokens []Token, stats TrainStats) *Result {
+	freq := make(map[string]int)
+	for i := range tokens {
+		tokens[i].ID = ranks[tokens[i].Text]
+		freq[tokens[i].Text]++
+	}
+
+	stats.Merges = len(merges)
+	return &Result{Vocab: vocabEntries(ranks, freq), Merges: merges, Tokens: tokens, Stats: stats}
+}
+
+// newCountsResult sestaví výsledek učení z tabulky četností slov: bez sekvence,
+// četnosti tokenů jsou součty četností slov, v jejichž segmentaci se token vyskytuje.
+func newCountsResult(ranks Ranks, merges []Merge, segs map[string][]string, counts map[string]int, alphabet int, start time.Time) *Result {
+	freq := make(map[string]int)
+	var stats TrainStats
+	for w, c := range counts {
+		for _, s := range segs[w] {
+			freq[s] += c
+		}
+		stats.Words += c
+		stats.Chars += c * utf8.RuneCountInString(w)
+	}
+	stats.Alphabet = alphabet
+	stats.Merges = len(merges)
+	stats.Duration = time.Since(start)
+	return &Result{Vocab: vocabEntries(ranks, freq), Merges: merges, Stats: stats}
+}
+
+// vocabEntries sestaví slovník seřazený podle ID.
+func vocabEntries(ranks Ranks, freq map[string]int) []VocabEntry {
+	vocab := make([]VocabEntry, 0, len(ranks))
+	for s, id := range ranks {
+		vocab = append(vocab, VocabEntry{ID: id, Token: s, Freq: freq[s]})
+	}
+	sort.Slice(vocab, func(i, j int) bool { return vocab[i].ID < vocab[j].ID })
+	return vocab
+}
+
+// textStats spočítá slova a znaky textu pro TrainStats.
+func textStats(text string, words int, alphabet int, start time.Time) TrainStats {
+	return TrainStats{
+		Words:    words,
+		Chars:    utf8.RuneCountInString(text),
+		Alphabet: alphabet,
+		Duration: time.Since(start),
+	}
+}
+
+// wordIndices přiřadí tokenům sekvence, která přechází přes mezery, index slova,
+// ve kterém token začíná. Mezera patří ke slovu před ní.
+func wordIndices(seq []string) []Token {
+	tokens := make([]Token, len(seq))
+	word := -1
+	inSpace := true
+	for i, s := range seq {
+		first := true
+		for _, r := range s {
+			space := unicode.IsSpace(r)
+			if !space && inSpace {
+				word++
+			}
+			inSpace = space
+			if first {
+				tokens[i].Word = max(word, 0)
+				first = false
+			}
+		}
+		tokens[i].Text = s
+	}
+	return tokens
+}
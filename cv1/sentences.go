@@ -0,0 +1,225 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultAbbreviations jsou běžné české a anglické zkratky (malými písmeny, bez
+// koncové tečky). Anglické zkratky, které jsou zároveň českými slovy („co“,
+// „no“), v seznamu nejsou.
+var defaultAbbreviations = []string{
+	// čeština
+	"např", "tj", "tzn", "tzv", "apod", "atd", "aj", "resp", "mj", "popř", "kupř",
+	"př", "str", "č", "odst", "písm", "viz", "srov", "cca", "min", "max",
+	"st", "ml", "r", "hod", "tel",
+	// angličtina
+	"jr", "sr", "vs", "etc", "e.g", "i.e", "inc", "ltd", "fig", "vol", "approx",
+}
+
+// defaultTitles jsou tituly a oslovení, po kterých obvykle následuje jméno.
+var defaultTitles = []string{
+	"prof", "doc", "ing", "mudr", "judr", "phdr", "rndr", "mgr", "bc", "p", "pí", "sv",
+	"dr", "mr", "mrs", "ms",
+}
+
+// closingPunct jsou znaky, které smí stát za koncovou interpunkcí věty
+// (uvozovky a závorky), např. `řekl: „Ano.“`.
+const closingPunct = `"'“”»«’)]`
+
+// openingPunct jsou znaky, které smí stát před prvním písmenem věty.
+const openingPunct = `"'„“«»‚‘([`
+
+// SentenceSplitter dělí text na věty podle koncové interpunkce. Tečka za
+// titulem, za iniciálou a za řadovou číslovkou, po které věta pokračuje
+// malým písmenem („5. května“), větu neukončí. Za zkratkou věta skončí jen
+// před velkým písmenem slova, které samo není zkratkou ani titulem
+// (ortografická heuristika Punkt): „Trvalo to 5 min. Pak…“.
+type SentenceSplitter struct {
+	// Abbreviations jsou zkratky malými písmeny bez koncové tečky.
+	Abbreviations map[string]bool
+	// Titles jsou zkratky, po kterých věta nekončí nikdy, ani před velkým
+	// písmenem („Dr. Smith“, „ul. Dlouhá“).
+	Titles map[string]bool
+}
+
+// NewSentenceSplitter vrátí dělič s vestavěnými českými a anglickými zkratkami
+// a tituly.
+func NewSentenceSplitter() *SentenceSplitter {
+	s := &SentenceSplitter{
+		Abbreviations: make(map[string]bool, len(defaultAbbreviations)),
+		Titles:        make(map[string]bool, len(defaultTitles)),
+	}
+	for _, a := range defaultAbbreviations {
+		s.Abbreviations[a] = true
+	}
+	for _, t := range defaultTitles {
+		s.Titles[t] = true
+	}
+	return s
+}
+
+// Split rozdělí text na věty. Věty jsou úseky původního textu bez okrajových
+// mezer; konec řádku sám větu neukončí.
+func (s *SentenceSplitter) Split(text string) []string {
+	fields := fieldPretokens(text)
+	var sentences []string
+	start := -1
+	for i, f := range fields {
+		if start < 0 {
+			start = f.off
+		}
+		var next string
+		if i+1 < len(fields) {
+			next = fields[i+1].text
+		}
+		if i+1 == len(fields) || s.endsSentence(f.text, next) {
+			sentences = append(sentences, text[start:f.off+len(f.text)])
+			start = -1
+		}
+	}
+	return sentences
+}
+
+// endsSentence rozhodne, zda slovo tok ukončuje větu, když po něm následuje next.
+func (s *SentenceSplitter) endsSentence(tok, next string) bool {
+	core := strings.TrimRight(tok, closingPunct)
+	if core == "" {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(core)
+	switch last {
+	case '!', '?', '…':
+		return true
+	case '.':
+	default:
+		return false
+	}
+
+	word := strings.TrimLeft(strings.TrimRight(core, "."), openingPunct)
+	if strings.HasSuffix(core, "...") || word == "" {
+		return startsUpper(next)
+	}
+	if s.Titles[strings.ToLower(word)] {
+		return false
+	}
+	if s.Abbreviations[strings.ToLower(word)] {
+		return startsUpper(next) && !s.isAbbreviation(next)
+	}
+	// iniciála ("J. Novák")
+	if utf8.RuneCountInString(word) == 1 && unicode.IsUpper([]rune(word)[0]) {
+		return false
+	}
+	// řadová číslovka nebo tečka uvnitř věty: pokračuje malým písmenem či číslem
+	return !startsLowerOrDigit(next)
+}
+
+// isAbbreviation hlásí, zda je slovo (bez úvodních uvozovek a koncové tečky)
+// známou zkratkou nebo titulem.
+func (s *SentenceSplitter) isAbbreviation(w string) bool {
+	w = strings.ToLower(strings.TrimSuffix(strings.TrimLeft(w, openingPunct), "."))
+	return s.Abbreviations[w] || s.Titles[w]
+}
+
+// startsUpper hlásí, zda slovo (po případných úvodních uvozovkách) začíná velkým písmenem.
+func startsUpper(w string) bool {
+	r, _ := utf8.DecodeRuneInString(strings.TrimLeft(w, openingPunct))
+	return unicode.IsUpper(r)
+}
+
+func startsLowerOrDigit(w string) bool {
+	r, _ := utf8.DecodeRuneInString(strings.TrimLeft(w, openingPunct))
+	return unicode.IsLower(r) || unicode.IsDigit(r)
+}
+
+// LearnAbbreviations najde zkratky v textu bez učitele podle zjednodušené
+// heuristiky Punkt (Kiss & Strunk, 2006): slovo je zkratka, pokud se aspoň
+// minCount krát vyskytne s tečkou, téměř nikdy bez ní a je krátké (nebo
+// obsahuje vnitřní tečku jako "e.g"). Zkratky, po kterých pokračuje malé
+// písmeno, pozná Split i bez učení; učení pomůže hlavně u zkratek před
+// velkým písmenem ("ul. Dlouhá"), proto nalezené zkratky přidá do děliče
+// mezi tituly. Vrátí je seřazené.
+func (s *SentenceSplitter) LearnAbbreviations(text string, minCount int) []string {
+	type counts struct{ withPeriod, without int }
+	stats := make(map[string]*counts)
+	get := func(w string) *counts {
+		c, ok := stats[w]
+		if !ok {
+			c = &counts{}
+			stats[w] = c
+		}
+		return c
+	}
+
+	for _, f := range strings.Fields(text) {
+		core := strings.TrimLeft(strings.TrimRight(f, closingPunct), openingPunct)
+		if strings.HasSuffix(core, "..") {
+			continue
+		}
+		word := strings.ToLower(strings.TrimSuffix(core, "."))
+		if word == "" || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		c := get(word)
+		if !strings.HasSuffix(core, ".") {
+			c.without++
+			continue
+		}
+		c.withPeriod++
+	}
+
+	var learned []string
+	for w, c := range stats {
+		if c.withPeriod < minCount || s.Abbreviations[w] || s.Titles[w] {
+			continue
+		}
+		ratio := float64(c.withPeriod) / float64(c.withPeriod+c.without)
+		short := utf8.RuneCountInString(w) <= 4 || strings.Contains(w, ".")
+		if ratio >= 0.9 && short {
+			if s.Titles == nil {
+				s.Titles = make(map[string]bool)
+			}
+			s.Titles[w] = true
+			learned = append(learned, w)
+		}
+	}
+	sort.Strings(learned)
+	return learned
+}
+
+// SentenceStats jsou statistiky vět textu; délky jsou v počtu slov.
+type SentenceStats struct {
+	Count     int     `json:"count"`
+	AvgLength float64 `json:"avg_length"`
+	MinLength int     `json:"min_length"`
+	MaxLength int     `json:"max_length"`
+	// Lengths je histogram délek vět (index = počet slov).
+	Lengths []int `json:"lengths"`
+}
+
+// ComputeSentenceStats rozdělí text na věty a spočítá jejich statistiky. Text
+// se má předat před clean(), která smaže velká písmena, podle kterých se
+// rozpoznávají začátky vět.
+func ComputeSentenceStats(text string, s *SentenceSplitter) SentenceStats {
+	var st SentenceStats
+	var total int
+	for _, sent := range s.Split(text) {
+		n := len(strings.Fields(sent))
+		st.Count++
+		total += n
+		if st.Count == 1 || n < st.MinLength {
+			st.MinLength = n
+		}
+		st.MaxLength = max(st.MaxLength, n)
+		for len(st.Lengths) <= n {
+			st.Lengths = append(st.Lengths, 0)
+		}
+		st.Lengths[n]++
+	}
+	if st.Count > 0 {
+		st.AvgLength = float64(total) / float64(st.Count)
+	}
+	return st
+}
@@ -1,17 +1,47 @@
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/ajrac/MATD/stopwords"
 )
 
-type pair struct {
-	Word  string
-	Count int
+// WordCount je slovo s jeho četností.
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// CorpusStats jsou souhrnné statistiky slov textu.
+type CorpusStats struct {
+	Words  int `json:"words"`
+	Unique int `json:"unique"`
+	// Top jsou nejčetnější slova sestupně podle četnosti, při shodě abecedně.
+	Top []WordCount `json:"top"`
+	// TopFiltered jsou nejčetnější slova bez stop slov (jen se seznamem stop slov).
+	TopFiltered []WordCount `json:"top_filtered,omitempty"`
+	// Zipf a Heaps jsou proložení zákonů přes všechna slova, nejen Top.
+	Zipf  ZipfFit  `json:"zipf"`
+	Heaps HeapsFit `json:"heaps"`
+	// Diversity jsou míry lexikální rozmanitosti celého textu.
+	Diversity LexicalDiversity `json:"diversity"`
+	// Chars jsou statistiky znaků a znakových n-gramů se stejným top.
+	Chars CharStats `json:"chars"`
+	// Sentences jsou statistiky vět; počítají se z textu před clean(), proto je
+	// doplňuje volající (viz ComputeSentenceStats).
+	Sentences *SentenceStats `json:"sentences,omitempty"`
 }
 
-func printStatistics(text string) {
+// ComputeStatistics spočítá statistiky slov textu s nejvýše top nejčetnějšími
+// slovy (top < 0 = všechna slova). Se seznamem stop slov doplní i TopFiltered;
+// ostatní statistiky se počítají ze všech slov.
+func ComputeStatistics(text string, top int, stop stopwords.List) CorpusStats {
 	tokens := strings.Fields(text)
 
 	freq := make(map[string]int)
@@ -19,12 +49,28 @@ func printStatistics(text string) {
 		freq[w]++
 	}
 
-	total := len(tokens)
-	unique := len(freq)
+	pairs := topCounts(freq, -1)
+
+	s := CorpusStats{Words: len(tokens), Unique: len(freq), Top: pairs}
+	s.Zipf = fitZipf(pairs)
+	s.Heaps = fitHeaps(vocabGrowth(tokens))
+	s.Diversity = computeDiversity(tokens, freq)
+	s.Chars = computeCharStats(text, tokens, top)
+	if stop != nil {
+		s.TopFiltered = filterCounts(pairs, stop, top)
+	}
+	if top >= 0 && top < len(pairs) {
+		s.Top = pairs[:top]
+	}
+	return s
+}
 
-	pairs := make([]pair, 0, len(freq))
+// topCounts seřadí četnosti sestupně, při shodě abecedně, a vrátí nejvýše top
+// položek (top < 0 = všechny).
+func topCounts(freq map[string]int, top int) []WordCount {
+	pairs := make([]WordCount, 0, len(freq))
 	for w, c := range freq {
-		pairs = append(pairs, pair{w, c})
+		pairs = append(pairs, WordCount{w, c})
 	}
 	sort.Slice(pairs, func(i, j int) bool {
 		if pairs[i].Count == pairs[j].Count {
@@ -32,16 +78,99 @@ func printStatistics(text string) {
 		}
 		return pairs[i].Count > pairs[j].Count
 	})
+	if top >= 0 && top < len(pairs) {
+		pairs = pairs[:top]
+	}
+	return pairs
+}
+
+// filterCounts vrátí nejvýše top položek, které nejsou stop slovy (top < 0 = všechny).
+func filterCounts(pairs []WordCount, stop stopwords.List, top int) []WordCount {
+	var out []WordCount
+	for _, p := range pairs {
+		if top >= 0 && len(out) == top {
+			break
+		}
+		if !stop.Contains(p.Word) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
 
-	limit := 20
-	if limit > len(pairs) {
-		limit = len(pairs)
+// WriteText zapíše statistiky jako textovou tabulku. Řádky s četnostmi umí
+// znovu načíst ReadWordCounts.
+func (s CorpusStats) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+	fmt.Fprintln(ew, "Počet slov:", s.Words)
+	fmt.Fprintln(ew, "Počet unikátních slov:", s.Unique)
+	fmt.Fprintf(ew, "%d nejčetnějších slov:\n", len(s.Top))
+	for i, p := range s.Top {
+		fmt.Fprintf(ew, "%2d. %q — %d\n", i+1, p.Word, p.Count)
 	}
+	if s.TopFiltered != nil {
+		fmt.Fprintln(ew, "Bez stop slov:", formatCounts(s.TopFiltered))
+	}
+	fmt.Fprintf(ew, "Zipf: exponent %.3f (R² %.3f), MLE exponent %.3f\n", s.Zipf.Exponent, s.Zipf.R2, s.Zipf.MLEExponent)
+	fmt.Fprintf(ew, "Heaps: K %.3f, β %.3f (R² %.3f)\n", s.Heaps.K, s.Heaps.Beta, s.Heaps.R2)
+	d := s.Diversity
+	fmt.Fprintf(ew, "TTR %.4f, root TTR %.3f, hapax %d, dis legomena %d\n", d.TTR, d.RootTTR, d.Hapax, d.Dis)
+	fmt.Fprintf(ew, "Yule K %.2f, Simpson D %.5f, MTLD %.2f, HD-D %.4f\n", d.YuleK, d.SimpsonD, d.MTLD, d.HDD)
+	c := s.Chars
+	fmt.Fprintf(ew, "Počet znaků: %d, písmen s diakritikou %.1f%%, průměrná délka slova %.2f\n", c.Chars, c.DiacriticsShare*100, c.AvgWordLength)
+	fmt.Fprintln(ew, "Znaky:", formatCounts(c.Top))
+	fmt.Fprintln(ew, "Bigramy:", formatCounts(c.Bigrams))
+	fmt.Fprintln(ew, "Trigramy:", formatCounts(c.Trigrams))
+	if st := s.Sentences; st != nil {
+		fmt.Fprintf(ew, "Počet vět: %d, průměrná délka %.2f slov (min %d, max %d)\n", st.Count, st.AvgLength, st.MinLength, st.MaxLength)
+	}
+	return ew.err
+}
+
+// errWriter si zapamatuje první chybu zápisu a další zápisy už neprovede, aby
+// stačilo zkontrolovat chybu jednou na konci.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+// WriteJSON zapíše statistiky jako JSON.
+func (s CorpusStats) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// WriteCSV zapíše nejčetnější slova jako CSV se sloupci rank, word, count.
+// Souhrnné počty se do CSV nezapisují, jsou v JSON a textovém výstupu.
+func (s CorpusStats) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"rank", "word", "count"})
+	for i, p := range s.Top {
+		cw.Write([]string{strconv.Itoa(i + 1), p.Word, strconv.Itoa(p.Count)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
 
-	fmt.Println("Počet slov:", total)
-	fmt.Println("Počet unikátních slov:", unique)
-	fmt.Println("20 nejčetnějších slov:")
-	for i := 0; i < limit; i++ {
-		fmt.Printf("%2d. %q — %d\n", i+1, pairs[i].Word, pairs[i].Count)
+// Write zapíše statistiky ve formátu text, json nebo csv.
+func (s CorpusStats) Write(w io.Writer, format string) error {
+	switch format {
+	case "text", "":
+		return s.WriteText(w)
+	case "json":
+		return s.WriteJSON(w)
+	case "csv":
+		return s.WriteCSV(w)
 	}
+	return fmt.Errorf("neznámý formát statistik %q (text, json, csv)", format)
 }
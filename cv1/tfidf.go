@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ajrac/MATD/stopwords"
+)
+
+// Document je jeden dokument kolekce.
+type Document struct {
+	Name string
+	Text string
+}
+
+// LoadDocuments načte kolekci dokumentů: je-li path adresář, každý soubor
+// v něm (bez podadresářů a skrytých souborů jako .DS_Store, seřazeno podle
+// jména) je dokument; jinak se soubor čte jako JSONL, viz ReadJSONLDocuments.
+func LoadDocuments(path string) ([]Document, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadJSONLDocuments(f)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var docs []Document
+	for _, e := range entries {
+		if !e.Type().IsRegular() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(path, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, Document{Name: e.Name(), Text: string(data)})
+	}
+	return docs, nil
+}
+
+// ReadJSONLDocuments načte dokumenty z JSONL: na každém řádku objekt s polem
+// "text" a volitelně "id" nebo "name" (řetězec nebo číslo). Bez jména se
+// dokument jmenuje podle čísla řádku. Prázdné řádky se přeskočí.
+func ReadJSONLDocuments(r io.Reader) ([]Document, error) {
+	var docs []Document
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var obj struct {
+			Text string          `json:"text"`
+			ID   json.RawMessage `json:"id"`
+			Name json.RawMessage `json:"name"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &obj); err != nil {
+			return nil, fmt.Errorf("dokumenty: řádek %d: %w", line, err)
+		}
+		name := rawName(obj.Name)
+		if name == "" {
+			name = rawName(obj.ID)
+		}
+		if name == "" {
+			name = strconv.Itoa(line)
+		}
+		docs = append(docs, Document{Name: name, Text: obj.Text})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// rawName převede JSON řetězec nebo číslo na jméno dokumentu.
+func rawName(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// TFScheme určuje váhu četnosti termu v dokumentu.
+type TFScheme int
+
+const (
+	RawTF       TFScheme = iota // tf
+	LogTF                       // 1 + ln tf
+	AugmentedTF                 // 0,5 + 0,5 · tf / max tf v dokumentu
+	BooleanTF                   // 1, pokud se term v dokumentu vyskytuje
+)
+
+// IDFScheme určuje váhu vzácnosti termu v kolekci.
+type IDFScheme int
+
+const (
+	StandardIDF IDFScheme = iota // ln(N / df)
+	SmoothIDF                    // ln(1 + N / df), kladná i pro term ve všech dokumentech
+	ProbIDF                      // max(0, ln((N - df) / df))
+)
+
+// Weighting je kombinace vah TF a IDF.
+type Weighting struct {
+	TF  TFScheme
+	IDF IDFScheme
+}
+
+// Keyword je term dokumentu s jeho četností, dokumentovou četností a váhou.
+type Keyword struct {
+	Term  string
+	TF    int
+	DF    int
+	Score float64
+}
+
+// Collection je kolekce dokumentů rozdělených na termy s dokumentovými četnostmi.
+type Collection struct {
+	Docs []Document
+	// DF je počet dokumentů, ve kterých se term vyskytuje.
+	DF    map[string]int
+	terms []map[string]int
+}
+
+// NewCollection rozdělí dokumenty na termy: malá písmena, bez okrajové
+// interpunkce, jen slova obsahující písmeno. Se seznamem stop slov se stop
+// slova vynechají.
+func NewCollection(docs []Document, stop stopwords.List) *Collection {
+	c := &Collection{Docs: docs, DF: make(map[string]int), terms: make([]map[string]int, len(docs))}
+	for i, d := range docs {
+		tf := make(map[string]int)
+		for _, w := range strings.Fields(clean(d.Text)) {
+			w = strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+			if strings.IndexFunc(w, unicode.IsLetter) < 0 || (stop != nil && stop.Contains(w)) {
+				continue
+			}
+			tf[w]++
+		}
+		for w := range tf {
+			c.DF[w]++
+		}
+		c.terms[i] = tf
+	}
+	return c
+}
+
+// Keywords vrátí nejvýše top termů dokumentu i seřazených sestupně podle
+// váhy TF-IDF, při shodě abecedně (top < 0 = všechny).
+func (c *Collection) Keywords(i int, w Weighting, top int) []Keyword {
+	tf := c.terms[i]
+	maxTF := 0
+	for _, n := range tf {
+		maxTF = max(maxTF, n)
+	}
+
+	kws := make([]Keyword, 0, len(tf))
+	for term, n := range tf {
+		df := c.DF[term]
+		kws = append(kws, Keyword{Term: term, TF: n, DF: df, Score: w.tf(n, maxTF) * w.idf(df, len(c.Docs))})
+	}
+	sort.Slice(kws, func(i, j int) bool {
+		if kws[i].Score != kws[j].Score {
+			return kws[i].Score > kws[j].Score
+		}
+		return kws[i].Term < kws[j].Term
+	})
+	if top >= 0 && top < len(kws) {
+		kws = kws[:top]
+	}
+	return kws
+}
+
+func (w Weighting) tf(n, maxTF int) float64 {
+	switch w.TF {
+	case LogTF:
+		return 1 + math.Log(float64(n))
+	case AugmentedTF:
+		return 0.5 + 0.5*float64(n)/float64(maxTF)
+	case BooleanTF:
+		return 1
+	}
+	return float64(n)
+}
+
+func (w Weighting) idf(df, docs int) float64 {
+	N, DF := float64(docs), float64(df)
+	switch w.IDF {
+	case SmoothIDF:
+		return math.Log(1 + N/DF)
+	case ProbIDF:
+		return math.Max(0, math.Log((N-DF)/DF))
+	}
+	return math.Log(N / DF)
+}
+
+func (s TFScheme) String() string {
+	switch s {
+	case RawTF:
+		return "raw"
+	case LogTF:
+		return "log"
+	case AugmentedTF:
+		return "augmented"
+	case BooleanTF:
+		return "boolean"
+	}
+	return fmt.Sprintf("TFScheme(%d)", int(s))
+}
+
+func (s TFScheme) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *TFScheme) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "raw", "":
+		*s = RawTF
+	case "log":
+		*s = LogTF
+	case "augmented":
+		*s = AugmentedTF
+	case "boolean":
+		*s = BooleanTF
+	default:
+		return fmt.Errorf("neznámé váhování TF %q (raw, log, augmented, boolean)", text)
+	}
+	return nil
+}
+
+func (s IDFScheme) String() string {
+	switch s {
+	case StandardIDF:
+		return "standard"
+	case SmoothIDF:
+		return "smooth"
+	case ProbIDF:
+		return "prob"
+	}
+	return fmt.Sprintf("IDFScheme(%d)", int(s))
+}
+
+func (s IDFScheme) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *IDFScheme) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "standard", "":
+		*s = StandardIDF
+	case "smooth":
+		*s = SmoothIDF
+	case "prob":
+		*s = ProbIDF
+	default:
+		return fmt.Errorf("neznámé váhování IDF %q (standard, smooth, prob)", text)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Formát .tiktoken: na každém řádku base64 zakódované byty tokenu, mezera a rank.
+// Naše tokeny jsou UTF-8 řetězce, zapisují se tedy jejich byty; počáteční
+// symboly ByteTokenizeru jsou celé znaky, takže česká písmena mají v souboru
+// víc bytů.
+
+// WriteTiktoken zapíše ranky ve formátu .tiktoken, seřazené podle ranku.
+// Zapíše se jen naučený slovník, ne všech 256 jednobytových tokenů: kdo
+// soubor čte po bytech (jako tiktoken), nezakóduje znak, který se v učicím
+// textu nevyskytl. Nepomůže ani Ranks.WithByteFallback, ta přidá byty jako
+// řetězce <0xNN>, ne jako samotné byty.
+func WriteTiktoken(w io.Writer, ranks Ranks) error {
+	bw := bufio.NewWriter(w)
+	for _, tok := range ranks.Tokens() {
+		if _, err := fmt.Fprintf(bw, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(tok)), ranks[tok]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadTiktoken načte ranky ze souboru ve formátu .tiktoken. Prázdné řádky se přeskočí.
+func ReadTiktoken(r io.Reader) (Ranks, error) {
+	ranks := make(Ranks)
+	seen := make(map[int]struct{})
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("tiktoken: řádek %d: očekávány 2 sloupce, nalezeno %d", line, len(fields))
+		}
+		tok, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("tiktoken: řádek %d: %w", line, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("tiktoken: řádek %d: %w", line, err)
+		}
+
+		if _, dup := ranks[string(tok)]; dup {
+			return nil, fmt.Errorf("tiktoken: řádek %d: token %q je v souboru vícekrát", line, tok)
+		}
+		if _, dup := seen[rank]; dup {
+			return nil, fmt.Errorf("tiktoken: řádek %d: rank %d je v souboru vícekrát", line, rank)
+		}
+		ranks[string(tok)] = rank
+		seen[rank] = struct{}{}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return ranks, nil
+}
+
+// loadTiktoken načte ranky ze souboru na dané cestě.
+func loadTiktoken(path string) (Ranks, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTiktoken(f)
+}
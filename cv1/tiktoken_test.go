@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTiktokenZapisACteni(t *testing.T) {
+	ranks := ByteTokenizer{}.Train(fallbackText+" příliš žluťoučký kůň", 50).Ranks()
+
+	var buf bytes.Buffer
+	if err := WriteTiktoken(&buf, ranks); err != nil {
+		t.Fatalf("WriteTiktoken: %v", err)
+	}
+	read, err := ReadTiktoken(&buf)
+	if err != nil {
+		t.Fatalf("ReadTiktoken: %v", err)
+	}
+
+	if len(read) != len(ranks) {
+		t.Fatalf("načteno %d tokenů, očekáváno %d", len(read), len(ranks))
+	}
+	for tok, r := range ranks {
+		if read[tok] != r {
+			t.Errorf("token %q: rank %d, očekáváno %d", tok, read[tok], r)
+		}
+	}
+}
+
+func TestTiktokenChybnyVstup(t *testing.T) {
+	inputs := []string{
+		"dGhl\n",           // chybí rank
+		"dGhl x\n",         // rank není číslo
+		"!!! 1\n",          // neplatný base64
+		"dGhl 1\ndGhl 2\n", // duplicitní token
+		"dGhl 1\nYQ== 1\n", // duplicitní rank
+	}
+	for _, in := range inputs {
+		if _, err := ReadTiktoken(strings.NewReader(in)); err == nil {
+			t.Errorf("ReadTiktoken(%q): očekávána chyba", in)
+		}
+	}
+}
+
+func TestTiktokenKodovani(t *testing.T) {
+	text := clean(fallbackText)
+	ranks := ByteTokenizer{}.Train(text, 100).Ranks()
+	_, trained := ByteTokenizer{}.Tokenize(text, 100)
+
+	enc := ByteTokenizer{}.Encoder(ranks)
+	seq := mustEncode(t, enc, text)
+	if got := strings.Join(seq, ""); got != text {
+		t.Fatalf("zakódovaný text se liší od původního")
+	}
+	for i, id := range enc.IDs(seq) {
+		if id < 0 {
+			t.Errorf("token %q není ve slovníku", seq[i])
+		}
+	}
+	t.Logf("Tokenů při učení: %d, při kódování podle ranků: %d", len(trained), len(seq))
+
+	// Byte-level slovník: znak mimo slovník se rozloží na byty
+	bytesOnly := Ranks{"\xc5": 0, "\x99": 1, "a": 2}
+	got := mustEncode(t, ByteTokenizer{}.Encoder(bytesOnly), "řa")
+	if len(got) != 3 || got[0] != "\xc5" || got[1] != "\x99" {
+		t.Errorf("očekávány byty [c5 99 a], dostáno %q", got)
+	}
+}
+
+// TestTiktokenSrovnani porovná externí .tiktoken slovník (proměnná prostředí
+// TIKTOKEN_PATH) se slovníkem ByteBPE naučeným na českém datasetu.
+func TestTiktokenSrovnani(t *testing.T) {
+	path := os.Getenv("TIKTOKEN_PATH")
+	if path == "" {
+		t.Skip("TIKTOKEN_PATH není nastavena")
+	}
+	external, err := loadTiktoken(path)
+	if err != nil {
+		t.Fatalf("načtení %s: %v", path, err)
+	}
+
+	r := loadTokenized(t)
+	shared := 0
+	for _, tok := range r.ByteVocab {
+		if _, ok := external[tok]; ok {
+			shared++
+		}
+	}
+
+	extSeq := mustEncode(t, ByteTokenizer{}.Encoder(external), r.Text)
+	numWords := len(strings.Fields(r.Text))
+
+	t.Logf("=== Srovnání s %s ===", path)
+	t.Logf("%-30s %15s %15s", "", "ByteBPE (cs)", "tiktoken")
+	t.Logf("%-30s %15d %15d", "Velikost slovníku", len(r.ByteVocab), len(external))
+	t.Logf("%-30s %15.2f %15.2f", "Tokenů na slovo", float64(len(r.ByteSeq))/float64(numWords), float64(len(extSeq))/float64(numWords))
+	t.Logf("Společných tokenů: %d", shared)
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"sort"
 	"strings"
+	"time"
+	"unicode"
 )
 
 type Merge struct {
@@ -10,12 +12,29 @@ type Merge struct {
 	B string
 }
 
-type Tokenizer interface {
-	Tokenize(text string, k int) ([]string, []string)
-}
+// WordMarker určuje, jak WordTokenizer vyznačuje hranice slov.
+type WordMarker int
+
+const (
+	// EndOfWord připojí ke každému slovu symbol <end_of_word>; původní mezery se ztratí.
+	EndOfWord WordMarker = iota
+	// SpacePrefix zakóduje mezeru jako ▁ na začátku následujícího slova
+	// (konvence SentencePiece), takže tokenizace je bezeztrátová.
+	SpacePrefix
+)
 
-type WordTokenizer struct{}
-type ByteTokenizer struct{}
+// spaceMarker nahrazuje mezeru v režimu SpacePrefix
+const spaceMarker = "▁"
+
+type WordTokenizer struct {
+	Marker WordMarker
+	// Allow omezuje, které páry se smí sloučit (nil = všechny), viz constraints.go.
+	Allow MergeConstraint
+}
+type ByteTokenizer struct {
+	// Allow omezuje, které páry se smí sloučit (nil = všechny), viz constraints.go.
+	Allow MergeConstraint
+}
 
 // llNode je uzel doubly-linked listu pro ByteTokenizer
 type llNode struct {
@@ -26,27 +45,55 @@ type llNode struct {
 }
 
 func (t WordTokenizer) Tokenize(text string, k int) ([]string, []string) {
-	fields := strings.Fields(text)
+	return t.Train(text, k).Legacy()
+}
 
-	freq := make(map[string]int)
-	for _, w := range fields {
-		freq[w]++
+// Train naučí slovník na textu; tokeny nesou index slova, ke kterému patří.
+func (t WordTokenizer) Train(text string, k int) *Result {
+	start := time.Now()
+	fields := t.words(text)
+	alphabet, merges, wordSeq := t.train(wordFrequencies(fields), k)
+
+	// sestavení celé tokenizované sekvence v pořadí původního textu
+	var tokens []Token
+	for i, w := range fields {
+		for _, s := range wordSeq[w] {
+			tokens = append(tokens, Token{Text: s, Word: i})
+		}
 	}
 
+	return newResult(rankMerges(alphabet, merges), merges, tokens, textStats(text, len(fields), len(alphabet), start))
+}
+
+// TrainCounts naučí slovník přímo z tabulky četností slov, např. načtené
+// pomocí ReadWordCounts. Výsledek nemá sekvenci tokenů; četnosti ve slovníku
+// jsou vážené četnostmi slov. V režimu SpacePrefix se předpokládá, že každému
+// slovu předchází mezera, slova tedy dostanou prefix ▁.
+func (t WordTokenizer) TrainCounts(counts map[string]int, k int) *Result {
+	start := time.Now()
+	freq := counts
+	if t.Marker == SpacePrefix {
+		freq = make(map[string]int, len(counts))
+		for w, c := range counts {
+			freq[spaceMarker+w] += c
+		}
+	}
+	alphabet, merges, wordSeq := t.train(freq, k)
+	return newCountsResult(rankMerges(alphabet, merges), merges, wordSeq, freq, len(alphabet), start)
+}
+
+// train provede K merge operací nad slovy s danými četnostmi a vrátí počáteční
+// abecedu, merge operace v pořadí a finální segmentaci každého slova.
+func (t WordTokenizer) train(freq map[string]int, k int) ([]string, []Merge, map[string][]string) {
 	// vytvořím mapu pro uložení sekvence symbolů pro každé slovo
 	wordSeq := make(map[string][]string, len(freq))
 	for w := range freq {
-		r := []rune(w)
-		syms := make([]string, 0, len(r)+1)
-		for _, rr := range r {
-			syms = append(syms, string(rr))
-		}
-		syms = append(syms, "<end_of_word>")
-		wordSeq[w] = syms
+		wordSeq[w] = t.symbols(w)
 	}
+	alphabet := symbolAlphabet(wordSeq)
 
 	// Počáteční frekvence párů (spočítám jednou)
-	pairCounts := pairFrequencies(wordSeq, freq)
+	pairCounts := pairFrequencies(wordSeq, freq, t.Allow)
 
 	merges := make([]Merge, 0, k)
 	for i := 0; i < k; i++ {
@@ -71,31 +118,27 @@ func (t WordTokenizer) Tokenize(text string, k int) ([]string, []string) {
 		merged := a + b
 		merges = append(merges, Merge{A: a, B: b})
 
-		updateWordPairCounts(wordSeq, freq, pairCounts, a, b, merged)
-	}
-
-	vocab := make(map[string]struct{})
-	for _, syms := range wordSeq {
-		for _, s := range syms {
-			vocab[s] = struct{}{} // empty struct{} is used to save memory, as it occupies zero bytes, map takes only uniques
-		}
+		updateWordPairCounts(wordSeq, freq, pairCounts, a, b, merged, t.Allow)
 	}
 
-	vocabList := make([]string, 0, len(vocab))
-	for s := range vocab {
-		vocabList = append(vocabList, s)
-	}
+	return alphabet, merges, wordSeq
+}
 
-	// sestavení celé tokenizované sekvence v pořadí původního textu
-	var sequence []string
-	for _, w := range fields {
-		sequence = append(sequence, wordSeq[w]...)
-	}
+func (t ByteTokenizer) Tokenize(text string, k int) ([]string, []string) {
+	return t.Train(text, k).Legacy()
+}
 
-	return vocabList, sequence
+// Train naučí slovník na celém textu; tokeny mohou přecházet přes mezery.
+func (t ByteTokenizer) Train(text string, k int) *Result {
+	start := time.Now()
+	alphabet, merges, syms := t.train(text, k)
+	stats := textStats(text, len(strings.Fields(text)), len(alphabet), start)
+	return newResult(rankMerges(alphabet, merges), merges, wordIndices(syms), stats)
 }
 
-func (t ByteTokenizer) Tokenize(text string, k int) ([]string, []string) {
+// train provede K merge operací nad celým textem a vrátí počáteční abecedu,
+// merge operace v pořadí a výslednou sekvenci tokenů.
+func (t ByteTokenizer) train(text string, k int) ([]string, []Merge, []string) {
 	// Inicializace linked listu + invertovaného indexu
 	var head *llNode
 	var tail *llNode
@@ -117,10 +160,18 @@ func (t ByteTokenizer) Tokenize(text string, k int) ([]string, []string) {
 		nodeIndex[s][node] = struct{}{}
 	}
 
+	alphabet := make([]string, 0, len(nodeIndex))
+	for s := range nodeIndex {
+		alphabet = append(alphabet, s)
+	}
+	sort.Strings(alphabet)
+
 	// Počáteční frekvence párů (spočítám jednou)
 	pairCounts := make(map[Merge]int)
 	for n := head; n != nil && n.next != nil; n = n.next {
-		pairCounts[Merge{A: n.val, B: n.next.val}]++
+		if p := (Merge{A: n.val, B: n.next.val}); t.Allow.allows(p) {
+			pairCounts[p]++
+		}
 	}
 
 	// K merge operací
@@ -146,25 +197,20 @@ func (t ByteTokenizer) Tokenize(text string, k int) ([]string, []string) {
 		merged := a + b
 		merges = append(merges, Merge{A: a, B: b})
 
-		updateBytePairCountsLL(pairCounts, nodeIndex, a, b, merged)
+		updateBytePairCountsLL(pairCounts, nodeIndex, a, b, merged, t.Allow)
 	}
 
-	// Unikátní slovník + sekvence z linked listu
-	vocab := make(map[string]struct{})
+	// Sekvence z linked listu
 	var syms []string
 	for n := head; n != nil; n = n.next {
 		syms = append(syms, n.val)
-		vocab[n.val] = struct{}{}
 	}
-
-	vocabList := make([]string, 0, len(vocab))
-	for s := range vocab {
-		vocabList = append(vocabList, s)
-	}
-	return vocabList, syms
+	return alphabet, merges, syms
 }
 
-func updateWordPairCounts(wordSeq map[string][]string, freq map[string]int, pairCounts map[Merge]int, a, b, merged string) {
+// Páry, které allow zakazuje, se do pairCounts vůbec nepřidávají, takže je nelze
+// vybrat k merge; odečítání s nimi počítá (chybějící pár se jen smaže).
+func updateWordPairCounts(wordSeq map[string][]string, freq map[string]int, pairCounts map[Merge]int, a, b, merged string, allow MergeConstraint) {
 	for w, syms := range wordSeq {
 		wt := freq[w]
 		if wt == 0 || len(syms) < 2 {
@@ -199,12 +245,14 @@ func updateWordPairCounts(wordSeq map[string][]string, freq map[string]int, pair
 		// Přidám nové páry po merge
 		for j := 0; j+1 < len(newSyms); j++ {
 			p := Merge{A: newSyms[j], B: newSyms[j+1]}
-			pairCounts[p] += wt
+			if allow.allows(p) {
+				pairCounts[p] += wt
+			}
 		}
 	}
 }
 
-func updateBytePairCountsLL(pairCounts map[Merge]int, nodeIndex map[string]map[*llNode]struct{}, a, b, merged string) {
+func updateBytePairCountsLL(pairCounts map[Merge]int, nodeIndex map[string]map[*llNode]struct{}, a, b, merged string, allow MergeConstraint) {
 	// Sesbírám kandidáty: uzly s hodnotou a, jejichž next má hodnotu b
 	candidates := make([]*llNode, 0)
 	for n := range nodeIndex[a] {
@@ -267,12 +315,16 @@ func updateBytePairCountsLL(pairCounts map[Merge]int, nodeIndex map[string]map[*
 		}
 		nodeIndex[merged][n] = struct{}{}
 
-		// Přidám nové páry
+		// Přidám nové páry (jen povolené)
 		if n.prev != nil {
-			pairCounts[Merge{A: n.prev.val, B: n.val}]++
+			if p := (Merge{A: n.prev.val, B: n.val}); allow.allows(p) {
+				pairCounts[p]++
+			}
 		}
 		if n.next != nil {
-			pairCounts[Merge{A: n.val, B: n.next.val}]++
+			if p := (Merge{A: n.val, B: n.next.val}); allow.allows(p) {
+				pairCounts[p]++
+			}
 		}
 	}
 }
@@ -285,7 +337,7 @@ func decrementPair(pairCounts map[Merge]int, a, b string) {
 	}
 }
 
-func pairFrequencies(wordSeq map[string][]string, freq map[string]int) map[Merge]int {
+func pairFrequencies(wordSeq map[string][]string, freq map[string]int, allow MergeConstraint) map[Merge]int {
 	pairCounts := make(map[Merge]int)
 	for w, syms := range wordSeq {
 		wt := freq[w]
@@ -294,7 +346,9 @@ func pairFrequencies(wordSeq map[string][]string, freq map[string]int) map[Merge
 		}
 		for i := 0; i+1 < len(syms); i++ {
 			p := Merge{A: syms[i], B: syms[i+1]}
-			pairCounts[p] += wt
+			if allow.allows(p) {
+				pairCounts[p] += wt
+			}
 		}
 	}
 	return pairCounts
@@ -314,3 +368,128 @@ func applyMerge(syms []string, a, b, merged string) []string {
 	}
 	return result
 }
+
+// Decode složí tokeny zpět na text. V režimu SpacePrefix stačí tokeny spojit
+// a nahradit ▁ mezerou; v režimu EndOfWord se slova oddělí jednou mezerou.
+func (t WordTokenizer) Decode(tokens []string) string {
+	text := strings.Join(tokens, "")
+	if t.Marker == SpacePrefix {
+		return strings.ReplaceAll(text, spaceMarker, " ")
+	}
+	return strings.TrimSuffix(strings.ReplaceAll(text, "<end_of_word>", " "), " ")
+}
+
+// wordFrequencies spočítá četnosti slov.
+func wordFrequencies(fields []string) map[string]int {
+	freq := make(map[string]int)
+	for _, w := range fields {
+		freq[w]++
+	}
+	return freq
+}
+
+// words rozdělí text na slova podle zvolené značky hranic.
+func (t WordTokenizer) words(text string) []string {
+	if t.Marker == SpacePrefix {
+		return spacePrefixWords(text)
+	}
+	return strings.Fields(text)
+}
+
+// symbols rozloží slovo na počáteční symboly: jednotlivé znaky a v režimu
+// EndOfWord ještě značku konce slova.
+func (t WordTokenizer) symbols(w string) []string {
+	r := []rune(w)
+	syms := make([]string, 0, len(r)+1)
+	for _, rr := range r {
+		syms = append(syms, string(rr))
+	}
+	if t.Marker == SpacePrefix {
+		return syms
+	}
+	return append(syms, "<end_of_word>")
+}
+
+// pretokens rozdělí text na slova stejně jako words, navíc s jejich offsety v textu.
+func (t WordTokenizer) pretokens(text string) []pretoken {
+	if t.Marker == SpacePrefix {
+		return spacePrefixPretokens(text)
+	}
+	return fieldPretokens(text)
+}
+
+// pretoken je slovo textu (v režimu SpacePrefix s mezerami nahrazenými ▁)
+// a bytový offset jeho začátku v původním textu.
+type pretoken struct {
+	text string
+	off  int
+}
+
+// fieldPretokens rozdělí text na slova oddělená bílými znaky (jako strings.Fields).
+func fieldPretokens(text string) []pretoken {
+	var out []pretoken
+	start := -1
+	for i, ch := range text {
+		if unicode.IsSpace(ch) {
+			if start >= 0 {
+				out = append(out, pretoken{text: text[start:i], off: start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		out = append(out, pretoken{text: text[start:], off: start})
+	}
+	return out
+}
+
+// spacePrefixPretokens nahradí každou mezeru znakem ▁ a text rozdělí před každým ▁,
+// např. "the cat" → ["the", "▁cat"]. Spojením slov vznikne původní text.
+func spacePrefixPretokens(text string) []pretoken {
+	var out []pretoken
+	start := 0
+	for i, ch := range text {
+		if ch == ' ' && i > start {
+			out = append(out, pretoken{text: text[start:i], off: start})
+			start = i
+		}
+	}
+	if start < len(text) {
+		out = append(out, pretoken{text: text[start:], off: start})
+	}
+	for i := range out {
+		out[i].text = strings.ReplaceAll(out[i].text, " ", spaceMarker)
+	}
+	return out
+}
+
+// spacePrefixWords vrátí slova textu v režimu SpacePrefix.
+func spacePrefixWords(text string) []string {
+	pieces := spacePrefixPretokens(text)
+	if len(pieces) == 0 {
+		return nil
+	}
+	words := make([]string, len(pieces))
+	for i, p := range pieces {
+		words[i] = p.text
+	}
+	return words
+}
+
+// symbolAlphabet vrátí seřazenou množinu symbolů, ze kterých se slova skládají.
+func symbolAlphabet(wordSeq map[string][]string) []string {
+	set := make(map[string]struct{})
+	for _, syms := range wordSeq {
+		for _, s := range syms {
+			set[s] = struct{}{}
+		}
+	}
+	alphabet := make([]string, 0, len(set))
+	for s := range set {
+		alphabet = append(alphabet, s)
+	}
+	sort.Strings(alphabet)
+	return alphabet
+}
@@ -2,11 +2,17 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"unicode"
 	"unicode/utf8"
+
+	"github.com/ajrac/MATD/stopwords"
 )
 
 // Výchozí cesta k českému datasetu (stejná jako v launch.json).
@@ -21,22 +27,30 @@ const fallbackText = `the cat sat on the mat the cat ate the rat and the bat sat
 
 const mergeOps = 1000
 
+// Morfessor s logaritmickým tlumením četností, aby se dělila i častá slova
+var morfessorTokenizer = MorfessorTokenizer{Dampening: LogDampening}
+
 // ---------- Načtení a čištění datasetu ----------
 
 var (
 	datasetOnce sync.Once
 	datasetText string
 
-	tokenizeOnce    sync.Once
-	cachedWordVocab []string
-	cachedWordSeq   []string
-	cachedByteVocab []string
-	cachedByteSeq   []string
-	cachedText      string
+	tokenizeOnce      sync.Once
+	cachedWordVocab   []string
+	cachedWordSeq     []string
+	cachedWordSPVocab []string
+	cachedWordSPSeq   []string
+	cachedByteVocab   []string
+	cachedByteSeq     []string
+	cachedMorfVocab   []string
+	cachedMorfSeq     []string
+	cachedResults     [4]*Result
+	cachedText        string
 )
 
 // loadDataset načte a vyčistí český dataset (jednou pro všechny testy).
-func loadDataset(t *testing.T) string {
+func loadDataset(t testing.TB) string {
 	t.Helper()
 	datasetOnce.Do(func() {
 		path := os.Getenv("DATASET_PATH")
@@ -56,12 +70,17 @@ func loadDataset(t *testing.T) string {
 	return datasetText
 }
 
-// tokenizeResult spustí oba tokenizery paralelně (jednou pro všechny testy)
-// a výsledky uloží do cache.
+// tokenizeResult spustí tokenizery paralelně (jednou pro všechny testy)
+// a výsledky uloží do cache. WordSP je WordBPE se značkou ▁ místo <end_of_word>,
+// Morf je Morfessor Baseline. Slovníky jsou jen použité tokeny jako z Tokenize,
+// Results jsou celé výsledky v pořadí Word, WordSP, Byte, Morf.
 type tokenizeResult struct {
-	WordVocab, WordSeq []string
-	ByteVocab, ByteSeq []string
-	Text               string
+	WordVocab, WordSeq     []string
+	WordSPVocab, WordSPSeq []string
+	ByteVocab, ByteSeq     []string
+	MorfVocab, MorfSeq     []string
+	Results                [4]*Result
+	Text                   string
 }
 
 func loadTokenized(t *testing.T) tokenizeResult {
@@ -70,15 +89,28 @@ func loadTokenized(t *testing.T) tokenizeResult {
 		cachedText = loadDataset(t)
 		//cachedText = truncateText(fullText, 5000)
 
-		cachedWordVocab, cachedWordSeq = WordTokenizer{}.Tokenize(cachedText, mergeOps)
-		cachedByteVocab, cachedByteSeq = ByteTokenizer{}.Tokenize(cachedText, mergeOps)
+		cachedResults = [4]*Result{
+			WordTokenizer{}.Train(cachedText, mergeOps),
+			WordTokenizer{Marker: SpacePrefix}.Train(cachedText, mergeOps),
+			ByteTokenizer{}.Train(cachedText, mergeOps),
+			morfessorTokenizer.Train(cachedText, mergeOps),
+		}
+		cachedWordVocab, cachedWordSeq = cachedResults[0].Legacy()
+		cachedWordSPVocab, cachedWordSPSeq = cachedResults[1].Legacy()
+		cachedByteVocab, cachedByteSeq = cachedResults[2].Legacy()
+		cachedMorfVocab, cachedMorfSeq = cachedResults[3].Legacy()
 	})
 	return tokenizeResult{
-		WordVocab: cachedWordVocab,
-		WordSeq:   cachedWordSeq,
-		ByteVocab: cachedByteVocab,
-		ByteSeq:   cachedByteSeq,
-		Text:      cachedText,
+		WordVocab:   cachedWordVocab,
+		WordSeq:     cachedWordSeq,
+		WordSPVocab: cachedWordSPVocab,
+		WordSPSeq:   cachedWordSPSeq,
+		ByteVocab:   cachedByteVocab,
+		ByteSeq:     cachedByteSeq,
+		MorfVocab:   cachedMorfVocab,
+		MorfSeq:     cachedMorfSeq,
+		Results:     cachedResults,
+		Text:        cachedText,
 	}
 }
 
@@ -112,7 +144,9 @@ func TestTokenizacniEfektivita(t *testing.T) {
 	r := loadTokenized(t)
 	text := r.Text
 	wordVocab, wordSeq := r.WordVocab, r.WordSeq
+	spVocab, spSeq := r.WordSPVocab, r.WordSPSeq
 	byteVocab, byteSeq := r.ByteVocab, r.ByteSeq
+	morfVocab, morfSeq := r.MorfVocab, r.MorfSeq
 
 	numChars := utf8.RuneCountInString(text)
 	numBytes := len(text)
@@ -120,20 +154,47 @@ func TestTokenizacniEfektivita(t *testing.T) {
 
 	// Počet tokenů na 1000 znaků
 	wordTokensPer1000 := float64(len(wordSeq)) / float64(numChars) * 1000
+	spTokensPer1000 := float64(len(spSeq)) / float64(numChars) * 1000
 	byteTokensPer1000 := float64(len(byteSeq)) / float64(numChars) * 1000
+	morfTokensPer1000 := float64(len(morfSeq)) / float64(numChars) * 1000
 
 	// Počet tokenů na slovo = (#tokenů v tokenizovaném textu) / (#slov v původním textu)
 	wordTokensPerWord := float64(len(wordSeq)) / float64(numWords)
+	spTokensPerWord := float64(len(spSeq)) / float64(numWords)
 	byteTokensPerWord := float64(len(byteSeq)) / float64(numWords)
+	morfTokensPerWord := float64(len(morfSeq)) / float64(numWords)
 
 	t.Logf("=== Tokenizační efektivita (K=%d) ===", mergeOps)
 	t.Logf("Délka textu: %d znaků, %d bytů, %d slov", numChars, numBytes, numWords)
 	t.Logf("")
-	t.Logf("%-25s %15s %15s", "", "WordBPE", "ByteBPE")
-	t.Logf("%-25s %15d %15d", "Velikost slovníku", len(wordVocab), len(byteVocab))
-	t.Logf("%-25s %15d %15d", "Počet tokenů v sekvenci", len(wordSeq), len(byteSeq))
-	t.Logf("%-25s %15.2f %15.2f", "Tokenů na 1000 znaků", wordTokensPer1000, byteTokensPer1000)
-	t.Logf("%-25s %15.2f %15.2f", "Tokenů na slovo", wordTokensPerWord, byteTokensPerWord)
+	t.Logf("%-25s %15s %15s %15s %15s", "", "WordBPE", "WordBPE ▁", "ByteBPE", "Morfessor")
+	t.Logf("%-25s %15d %15d %15d %15d", "Velikost slovníku", len(wordVocab), len(spVocab), len(byteVocab), len(morfVocab))
+	t.Logf("%-25s %15d %15d %15d %15d", "Počet tokenů v sekvenci", len(wordSeq), len(spSeq), len(byteSeq), len(morfSeq))
+	t.Logf("%-25s %15.2f %15.2f %15.2f %15.2f", "Tokenů na 1000 znaků", wordTokensPer1000, spTokensPer1000, byteTokensPer1000, morfTokensPer1000)
+	t.Logf("%-25s %15.2f %15.2f %15.2f %15.2f", "Tokenů na slovo", wordTokensPerWord, spTokensPerWord, byteTokensPerWord, morfTokensPerWord)
+
+	// Rozdělení tokenů nad celými slovníky včetně nepoužitých merge: samotný
+	// počet tokenů na slovo kvalitu nepředpovídá
+	var dists []TokenDistribution
+	for _, res := range r.Results {
+		dists = append(dists, res.Distribution(0, 5))
+	}
+	distRow := func(name string, f func(d TokenDistribution) float64) {
+		t.Logf("%-25s %15.3f %15.3f %15.3f %15.3f", name, f(dists[0]), f(dists[1]), f(dists[2]), f(dists[3]))
+	}
+	distRow("Entropie (bity)", func(d TokenDistribution) float64 { return d.Entropy })
+	distRow("Shannonova efektivita", func(d TokenDistribution) float64 { return d.Efficiency })
+	distRow(fmt.Sprintf("Rényiho efektivita α=%.1f", DefaultRenyiAlpha), func(d TokenDistribution) float64 { return d.RenyiEfficiency })
+	distRow("Podíl slovníku < 5×", func(d TokenDistribution) float64 { return d.RareShare })
+	t.Logf("")
+	t.Logf("%-25s %15s %15s %15s %15s", "Délka tokenu", "WordBPE", "WordBPE ▁", "ByteBPE", "Morfessor")
+	maxLen := 0
+	for _, d := range dists {
+		maxLen = max(maxLen, len(d.Lengths))
+	}
+	for l := 1; l < min(maxLen, 16); l++ {
+		t.Logf("%-25d %15d %15d %15d %15d", l, histAt(dists[0].Lengths, l), histAt(dists[1].Lengths, l), histAt(dists[2].Lengths, l), histAt(dists[3].Lengths, l))
+	}
 
 	// Základní sanity checky
 	if len(wordSeq) == 0 {
@@ -142,6 +203,35 @@ func TestTokenizacniEfektivita(t *testing.T) {
 	if len(byteSeq) == 0 {
 		t.Error("ByteTokenizer vrátil prázdnou sekvenci")
 	}
+	if len(morfSeq) == 0 {
+		t.Error("MorfessorTokenizer vrátil prázdnou sekvenci")
+	}
+
+	// Značka ▁ je bezeztrátová: dekódování vrátí přesně původní text
+	if got := (WordTokenizer{Marker: SpacePrefix}).Decode(spSeq); got != text {
+		t.Error("WordTokenizer (▁): dekódovaný text se liší od původního")
+	}
+	if got := (WordTokenizer{}).Decode(wordSeq); got != text {
+		t.Error("WordTokenizer: dekódovaný text se liší od původního")
+	}
+}
+
+func TestSpacePrefixSlova(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"the cat", []string{"the", "▁cat"}},
+		{" the cat ", []string{"▁the", "▁cat", "▁"}},
+		{"a  b", []string{"a", "▁", "▁b"}},
+		{"", nil},
+	}
+	for _, c := range cases {
+		got := spacePrefixWords(c.text)
+		if strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("spacePrefixWords(%q) = %q, očekáváno %q", c.text, got, c.want)
+		}
+	}
 }
 
 // ---------- Mezislovní tokeny ----------
@@ -211,6 +301,20 @@ func TestKvalitativniSrovnani(t *testing.T) {
 		}
 	}
 
+	t.Logf("")
+	t.Logf("--- WordBPE ▁ segmentace ---")
+
+	spSegMap := buildSpacePrefixSegMap(r.WordSPSeq, spacePrefixWords(text))
+
+	for _, w := range selectedWords {
+		seg, ok := spSegMap[w]
+		if ok {
+			t.Logf("  %-12s → [%s]", w, strings.Join(seg, " | "))
+		} else {
+			t.Logf("  %-12s → (nenalezeno)", w)
+		}
+	}
+
 	t.Logf("")
 	t.Logf("--- ByteBPE segmentace ---")
 
@@ -222,6 +326,110 @@ func TestKvalitativniSrovnani(t *testing.T) {
 			t.Logf("  %-12s → (nenalezeno)", w)
 		}
 	}
+
+	t.Logf("")
+	t.Logf("--- Morfessor segmentace ---")
+
+	morfSegMap := buildWordSegMap(r.MorfSeq, fields)
+
+	for _, w := range selectedWords {
+		seg, ok := morfSegMap[w]
+		if ok {
+			t.Logf("  %-12s → [%s]", w, strings.Join(seg, " | "))
+		} else {
+			t.Logf("  %-12s → (nenalezeno)", w)
+		}
+	}
+
+	t.Logf("")
+	t.Logf("--- MaxMatch segmentace (slovník WordBPE) ---")
+
+	mmSegMap := buildWordSegMap(MaxMatchTokenizer{Vocab: r.WordVocab}.Train(text, 0).Sequence(), fields)
+
+	for _, w := range selectedWords {
+		seg, ok := mmSegMap[w]
+		if ok {
+			t.Logf("  %-12s → [%s]", w, strings.Join(seg, " | "))
+		} else {
+			t.Logf("  %-12s → (nenalezeno)", w)
+		}
+	}
+}
+
+func TestMorfessorSegmentace(t *testing.T) {
+	// Malý korpus se sdílenými kmeny a koncovkami; každé slovo jednou
+	stems := []string{"hrad", "most", "stroj", "les", "park", "vlak", "strom", "kopec", "trh", "plot", "dub", "sad"}
+	suffixes := []string{"", "u", "em", "y", "ech", "ům", "ovi", "ů"}
+	var words []string
+	for _, s := range stems {
+		for _, x := range suffixes {
+			words = append(words, s+x)
+		}
+	}
+
+	m := MorfessorTokenizer{}.train(wordFrequencies(words))
+	for _, w := range []string{"hradech", "stromovi", "trhu"} {
+		seg := m.segment(w)
+		t.Logf("  %-12s → [%s]", w, strings.Join(seg, " | "))
+		if len(seg) < 2 {
+			t.Errorf("%s: očekáváno rozdělení na kmen a koncovku, dostáno %q", w, seg)
+		}
+	}
+
+	// Segmentace musí pokrýt celé slovo
+	for _, w := range words {
+		if got := strings.Join(m.segment(w), ""); got != w {
+			t.Errorf("segmentace %q dává %q", w, got)
+		}
+	}
+}
+
+// ---------- Morfologické hranice ----------
+
+// defaultGold je zlatá segmentace slov z kvalitativního srovnání; úplnější
+// soubor lze předat proměnnou prostředí GOLD_PATH.
+const defaultGold = "přípravek\tpří prav ek\n" +
+	"použití\tpo uži tí\n" +
+	"léčivý\tléč iv ý\n" +
+	"registrace\tregistr ace\n" +
+	"evropské\tevrop sk é\n" +
+	"může\tmůž e\n"
+
+func TestMorfologickeHranice(t *testing.T) {
+	var gold GoldSegmentation
+	var err error
+	if path := os.Getenv("GOLD_PATH"); path != "" {
+		gold, err = loadGoldSegmentation(path)
+	} else {
+		gold, err = ReadGoldSegmentation(strings.NewReader(defaultGold))
+	}
+	if err != nil {
+		t.Fatalf("načtení zlaté segmentace: %v", err)
+	}
+
+	r := loadTokenized(t)
+	seqs := []struct {
+		name string
+		seq  []string
+	}{
+		{"WordBPE", r.WordSeq},
+		{"WordBPE ▁", r.WordSPSeq},
+		{"ByteBPE", r.ByteSeq},
+		{"Morfessor", r.MorfSeq},
+		{"MaxMatch", MaxMatchTokenizer{Vocab: r.WordVocab}.Train(r.Text, 0).Sequence()},
+	}
+
+	t.Logf("=== Morfologické hranice (K=%d, %d slov ve zlatém standardu) ===", mergeOps, len(gold))
+	t.Logf("%-12s %8s %10s %10s %10s", "", "Slov", "Přesnost", "Úplnost", "F1")
+	for _, s := range seqs {
+		pred, err := segmentWords(s.seq, r.Text)
+		if err != nil {
+			t.Errorf("%s: %v", s.name, err)
+			continue
+		}
+		score := scoreBoundaries(pred, gold)
+		t.Logf("%-12s %8d %10.3f %10.3f %10.3f", s.name, score.Words, score.Precision, score.Recall, score.F1)
+	}
 }
 
 // ---------- Vliv K na velikost slovníku ----------
@@ -284,6 +492,28 @@ func buildWordSegMap(seq []string, fields []string) map[string][]string {
 	return result
 }
 
+// buildSpacePrefixSegMap mapuje každé slovo (bez značky ▁) na jeho tokeny.
+// Tokeny v režimu SpacePrefix nepřekračují hranice slov, takže stačí
+// odebírat tokeny, dokud nepokryjí celé slovo.
+func buildSpacePrefixSegMap(seq []string, words []string) map[string][]string {
+	result := make(map[string][]string)
+	pos := 0
+	for _, w := range words {
+		var group []string
+		covered := 0
+		for pos < len(seq) && covered < len(w) {
+			group = append(group, seq[pos])
+			covered += len(seq[pos])
+			pos++
+		}
+		key := strings.TrimPrefix(w, spaceMarker)
+		if _, seen := result[key]; !seen {
+			result[key] = group
+		}
+	}
+	return result
+}
+
 // extractByteSegmentation najde první izolovaný výskyt slova (ohraničený
 // mezerou nebo okrajem textu) v rekonstruovaném textu z byteSeq a vrátí
 // tokeny, které pokrývají přesně toto slovo (bez okolních mezer).
@@ -337,3 +567,684 @@ func extractByteSegmentation(seq []string, word string) []string {
 	}
 	return result
 }
+
+// ---------- Strukturovaný výsledek ----------
+
+func TestVysledekTokenizace(t *testing.T) {
+	text := "léčivý přípravek může být léčivý"
+	words := strings.Fields(text)
+	for _, tok := range []Tokenizer{WordTokenizer{}, ByteTokenizer{}, morfessorTokenizer} {
+		res := tok.Train(text, 20)
+
+		sum := 0
+		for i, v := range res.Vocab {
+			if i > 0 && v.ID <= res.Vocab[i-1].ID {
+				t.Errorf("%T: slovník není seřazený podle ID", tok)
+			}
+			sum += v.Freq
+		}
+		if sum != len(res.Tokens) {
+			t.Errorf("%T: součet četností %d, tokenů %d", tok, sum, len(res.Tokens))
+		}
+
+		// Indexy slov neklesají a tokeny mají ID ze slovníku
+		byWord := make([]string, len(words))
+		for i, tk := range res.Tokens {
+			if res.Vocab[tk.ID].Token != tk.Text {
+				t.Errorf("%T: token %q má ID %d", tok, tk.Text, tk.ID)
+			}
+			if i > 0 && tk.Word < res.Tokens[i-1].Word {
+				t.Errorf("%T: index slova klesá u tokenu %d", tok, i)
+			}
+			byWord[tk.Word] += stripWordMarkers(tk.Text)
+		}
+		// U tokenizerů po slovech dávají tokeny jednoho slova dohromady to slovo
+		if _, crossesWords := tok.(ByteTokenizer); !crossesWords {
+			for i, w := range words {
+				if byWord[i] != w {
+					t.Errorf("%T: slovo %d je %q, tokeny dávají %q", tok, i, w, byWord[i])
+				}
+			}
+		}
+
+		if res.Stats.Words != len(words) || res.Stats.Merges != len(res.Merges) {
+			t.Errorf("%T: neočekávané statistiky %+v", tok, res.Stats)
+		}
+
+		// Adaptér na původní rozhraní
+		vocab, seq := Legacy(tok).Tokenize(text, 20)
+		if len(seq) != len(res.Tokens) || len(vocab) == 0 {
+			t.Errorf("%T: Legacy vrátil %d tokenů, očekáváno %d", tok, len(seq), len(res.Tokens))
+		}
+	}
+}
+
+// ---------- Učení z tabulky četností ----------
+
+func TestUceniZCetnosti(t *testing.T) {
+	input := "Počet slov: 7\n" +
+		" 1. \"léčivý\" — 3\n" +
+		" 2. \"přípravek\" — 2\n" +
+		"# komentář\n" +
+		"léčivý\t1\n" +
+		"může\t1\n"
+	counts, err := ReadWordCounts(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"léčivý": 4, "přípravek": 2, "může": 1}
+	if len(counts) != len(want) {
+		t.Fatalf("načteno %v, očekáváno %v", counts, want)
+	}
+	for w, c := range want {
+		if counts[w] != c {
+			t.Errorf("%s: %d, očekáváno %d", w, counts[w], c)
+		}
+	}
+	if _, err := ReadWordCounts(strings.NewReader("slovo\tx\n")); err == nil {
+		t.Error("očekávána chyba pro nečíselnou četnost")
+	}
+
+	for _, tok := range []CountsTrainer{WordTokenizer{}, WordTokenizer{Marker: SpacePrefix}, morfessorTokenizer} {
+		res := tok.TrainCounts(counts, 10)
+		if res.Stats.Words != 7 || len(res.Tokens) != 0 {
+			t.Errorf("%T: %d slov, %d tokenů", tok, res.Stats.Words, len(res.Tokens))
+		}
+		sum := 0
+		for _, v := range res.Vocab {
+			sum += v.Freq
+		}
+		if sum < res.Stats.Words {
+			t.Errorf("%T: součet četností tokenů %d je menší než počet slov", tok, sum)
+		}
+	}
+
+	// Morfessor je deterministický: tabulka četností dá stejný slovník jako text
+	text := loadDataset(t)
+	fromText := morfessorTokenizer.Train(text, 0)
+	fromCounts := morfessorTokenizer.TrainCounts(wordFrequencies(strings.Fields(text)), 0)
+	if len(fromText.Vocab) != len(fromCounts.Vocab) {
+		t.Fatalf("slovník z textu má %d tokenů, z četností %d", len(fromText.Vocab), len(fromCounts.Vocab))
+	}
+	for i, v := range fromText.Vocab {
+		if fromCounts.Vocab[i] != v {
+			t.Errorf("token %d: z textu %+v, z četností %+v", i, v, fromCounts.Vocab[i])
+		}
+	}
+}
+
+// ---------- Registr tokenizerů ----------
+
+func TestRegistrTokenizeru(t *testing.T) {
+	text := loadDataset(t)
+	for _, name := range TokenizerNames() {
+		tok, err := NewTokenizer(name, TokenizerOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if res := tok.Train(truncateText(text, 1000), 10); len(res.Tokens) == 0 {
+			t.Errorf("%s vrátil prázdnou sekvenci", name)
+		}
+	}
+
+	if _, err := NewTokenizer("neexistuje", TokenizerOptions{}); err == nil {
+		t.Error("očekávána chyba pro neznámý tokenizer")
+	}
+
+	cfg, err := ReadTokenizerConfig(strings.NewReader(`{"tokenizer": "word-bpe", "k": 50, "options": {"marker": "space_prefix"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := cfg.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wt, ok := tok.(WordTokenizer); !ok || wt.Marker != SpacePrefix || cfg.K != 50 {
+		t.Errorf("konfigurace vytvořila %#v, K=%d", tok, cfg.K)
+	}
+
+	for _, bad := range []string{
+		`{"tokenizer": "word-bpe", "options": {"marker": "xyz"}}`,
+		`{"tokenizer": "word-bpe", "merges": 10}`,
+	} {
+		if _, err := ReadTokenizerConfig(strings.NewReader(bad)); err == nil {
+			t.Errorf("očekávána chyba pro %s", bad)
+		}
+	}
+
+	// Bez "k" by se BPE nenaučil žádnou merge operaci; Morfessor a max-match
+	// s externím slovníkem k nepotřebují
+	vocabPath := filepath.Join(t.TempDir(), "vocab.txt")
+	if err := os.WriteFile(vocabPath, []byte("ko\nčka\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		config string
+		ok     bool
+	}{
+		{`{"tokenizer": "word-bpe"}`, false},
+		{`{"tokenizer": "max-match"}`, false},
+		{`{"tokenizer": "morfessor"}`, true},
+		{fmt.Sprintf(`{"tokenizer": "max-match", "options": {"vocab_file": %q}}`, vocabPath), true},
+	} {
+		cfg, err := ReadTokenizerConfig(strings.NewReader(tt.config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cfg.New(); (err == nil) != tt.ok {
+			t.Errorf("%s: chyba %v", tt.config, err)
+		}
+	}
+}
+
+func TestOmezeniMerge(t *testing.T) {
+	text := "rok 2024, rok 2025. Kočka1 kočka2 kočka3! " + truncateText(loadDataset(t), 20000)
+
+	models := []struct {
+		name string
+		tok  Tokenizer
+		ok   MergeConstraint
+	}{
+		{"WordBPE", WordTokenizer{Allow: SeparateDigitsPunct.Constraint()}, SeparateDigitsPunct.Constraint()},
+		{"ByteBPE", ByteTokenizer{Allow: StrictClasses.Constraint()}, StrictClasses.Constraint()},
+	}
+	for _, m := range models {
+		res := m.tok.Train(text, 300)
+		for _, mg := range res.Merges {
+			if !m.ok(mg) {
+				t.Errorf("%s: zakázaný merge %q + %q", m.name, mg.A, mg.B)
+			}
+		}
+		for _, tok := range res.Tokens {
+			s := stripMarkers(tok.Text)
+			if strings.IndexFunc(s, unicode.IsDigit) >= 0 && strings.IndexFunc(s, unicode.IsLetter) >= 0 {
+				t.Errorf("%s: token %q spojuje písmena s číslicemi", m.name, tok.Text)
+			}
+		}
+		t.Logf("%s: %d merge operací, %d tokenů", m.name, len(res.Merges), len(res.Tokens))
+	}
+
+	if NoBoundaryCrossing(Space)(Merge{A: "a", B: " "}) || !NoBoundaryCrossing(Space)(Merge{A: " ", B: "a"}) {
+		t.Error("NoBoundaryCrossing: mezera smí být jen na začátku tokenu")
+	}
+}
+
+// TestSrovnaniSlovniku formalizuje srovnání z TestKvalitativniSrovnani.
+func TestSrovnaniSlovniku(t *testing.T) {
+	text := truncateText(loadDataset(t), 20000)
+	word := WordTokenizer{}.Train(text, mergeOps)
+	byteRes := ByteTokenizer{}.Train(text, mergeOps)
+
+	c := CompareVocabs("WordBPE", word, "ByteBPE", byteRes)
+	if c.Shared+len(c.OnlyA) != c.SizeA || c.Shared+len(c.OnlyB) != c.SizeB {
+		t.Errorf("nesedí počty: %d společných, %d+%d unikátních, velikosti %d × %d",
+			c.Shared, len(c.OnlyA), len(c.OnlyB), c.SizeA, c.SizeB)
+	}
+	if c.Jaccard <= 0 || c.Jaccard > 1 {
+		t.Errorf("Jaccard %.3f mimo (0, 1]", c.Jaccard)
+	}
+
+	// Stejný slovník se sebou: Jaccard 1 a nic unikátního
+	if self := CompareVocabs("A", word, "B", word); self.Jaccard != 1 || len(self.OnlyA)+len(self.OnlyB) > 0 {
+		t.Errorf("srovnání se sebou: Jaccard %.3f, %d unikátních", self.Jaccard, len(self.OnlyA)+len(self.OnlyB))
+	}
+
+	var sb strings.Builder
+	c.Print(&sb, 10)
+	for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+		t.Log(line)
+	}
+
+	sb.Reset()
+	if err := c.WriteCSV(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if rows := strings.Count(sb.String(), "\n"); rows != c.SizeA+c.SizeB-c.Shared+1 {
+		t.Errorf("CSV má %d řádků, očekáváno %d", rows, c.SizeA+c.SizeB-c.Shared+1)
+	}
+}
+
+func TestVicejazycneUceni(t *testing.T) {
+	cs := Corpus{Lang: "cs", Counts: wordFrequencies(strings.Fields(truncateText(loadDataset(t), 20000)))}
+	en := Corpus{Lang: "en", Counts: wordFrequencies(strings.Fields("the quick brown fox jumps over the lazy dog"))}
+	corpora := []Corpus{cs, en}
+
+	// T = 1 zachová poměr velikostí, vyšší teplota podíly vyrovnává
+	w1 := SamplingWeights(corpora, 1)
+	w5 := SamplingWeights(corpora, 5)
+	if math.Abs(w1[0]+w1[1]-1) > 1e-9 || w5[1] <= w1[1] {
+		t.Errorf("váhy T=1 %v, T=5 %v: vyšší teplota má posílit menší korpus", w1, w5)
+	}
+
+	const k = 50
+	t.Logf("=== Společný slovník cs+en (K=%d) ===", k)
+	for _, temp := range []float64{1, 5} {
+		_, stats, err := WordTokenizer{}.TrainMultilingual(corpora, k, temp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sb strings.Builder
+		PrintLanguageStats(&sb, stats)
+		t.Logf("T=%.0f", temp)
+		for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+			t.Log(line)
+		}
+		for _, s := range stats {
+			if s.Fertility < 1 {
+				t.Errorf("%s: fertilita %.3f < 1", s.Lang, s.Fertility)
+			}
+		}
+	}
+}
+
+func TestMaxMatch(t *testing.T) {
+	vocab, err := ReadVocabList(strings.NewReader("# slovník\nko\nkočk\t12\na\nka<end_of_word>\n▁ž\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := MaxMatchTokenizer{Vocab: vocab}.Train("kočka kočky žije", 0)
+	want := "kočk|a<end_of_word>|kočk|y<end_of_word>|ž|i|j|e<end_of_word>"
+	if got := strings.Join(res.Sequence(), "|"); got != want {
+		t.Errorf("MaxMatch: %s, očekáváno %s", got, want)
+	}
+
+	// V režimu SpacePrefix nese ▁ první token slova a text jde složit zpět
+	res = MaxMatchTokenizer{Vocab: vocab, Marker: SpacePrefix}.Train("kočka kočky žije", 0)
+	want = "kočk|a|▁kočk|y|▁ž|i|j|e"
+	if got := strings.Join(res.Sequence(), "|"); got != want {
+		t.Errorf("MaxMatch ▁: %s, očekáváno %s", got, want)
+	}
+	tok, err := NewTokenizer("max-match", TokenizerOptions{Marker: SpacePrefix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mm := tok.(MaxMatchTokenizer); mm.Marker != SpacePrefix {
+		t.Errorf("max-match z registru má značku %v", mm.Marker)
+	}
+
+	// Bez pevného slovníku se slovník naučí WordBPE
+	text := truncateText(loadDataset(t), 20000)
+	learned := MaxMatchTokenizer{}.Train(text, mergeOps)
+	bpe := WordTokenizer{}.Train(text, mergeOps)
+	t.Logf("MaxMatch: %d tokenů, WordBPE: %d tokenů", len(learned.Tokens), len(bpe.Tokens))
+	if len(learned.Tokens) < len(strings.Fields(text)) {
+		t.Errorf("MaxMatch dal méně tokenů (%d) než je slov", len(learned.Tokens))
+	}
+}
+
+func TestStatistikyKorpusu(t *testing.T) {
+	s := ComputeStatistics("b a c a b a", 2, nil)
+	if s.Words != 6 || s.Unique != 3 || len(s.Top) != 2 || s.Top[0] != (WordCount{"a", 3}) || s.Top[1] != (WordCount{"b", 2}) {
+		t.Errorf("statistiky %+v", s)
+	}
+
+	// Textový výpis se dá znovu načíst jako tabulka četností
+	var sb strings.Builder
+	if err := s.Write(&sb, "text"); err != nil {
+		t.Fatal(err)
+	}
+	counts, err := ReadWordCounts(strings.NewReader(sb.String()))
+	if err != nil || counts["a"] != 3 || counts["b"] != 2 || len(counts) != 2 {
+		t.Errorf("zpětné načtení výpisu: %v, %v", counts, err)
+	}
+
+	for _, format := range []string{"json", "csv"} {
+		sb.Reset()
+		if err := s.Write(&sb, format); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		t.Logf("%s:\n%s", format, sb.String())
+	}
+	if err := s.Write(&sb, "xml"); err == nil {
+		t.Error("očekávána chyba pro neznámý formát")
+	}
+
+	// Chyba hned prvního zápisu se neztratí, i když další zápisy projdou
+	if err := s.WriteText(&failOnceWriter{}); err == nil {
+		t.Error("očekávána chyba zápisu")
+	}
+}
+
+// failOnceWriter selže při prvním zápisu a pak už zapisuje bez chyby.
+type failOnceWriter struct{ failed bool }
+
+func (f *failOnceWriter) Write(p []byte) (int, error) {
+	if !f.failed {
+		f.failed = true
+		return 0, io.ErrShortWrite
+	}
+	return len(p), nil
+}
+
+func TestStopSlova(t *testing.T) {
+	stop := stopwords.Default()
+	for _, w := range []string{"a", "že", "The", "and"} {
+		if !stop.Contains(w) {
+			t.Errorf("%q má být stop slovo", w)
+		}
+	}
+	extra, err := stopwords.Read(strings.NewReader("# vlastní\nPřípravek\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop.Add(extra)
+
+	s := ComputeStatistics("přípravek a lék a a že lék dávka", 2, stop)
+	if len(s.TopFiltered) != 2 || s.TopFiltered[0] != (WordCount{"lék", 2}) || s.TopFiltered[1] != (WordCount{"dávka", 1}) {
+		t.Errorf("bez stop slov %v", s.TopFiltered)
+	}
+	if s.Top[0] != (WordCount{"a", 3}) {
+		t.Errorf("nefiltrovaný seznam %v", s.Top)
+	}
+	if ComputeStatistics("a b", 2, nil).TopFiltered != nil {
+		t.Error("bez seznamu stop slov nemá být TopFiltered")
+	}
+}
+
+func TestZipfHeaps(t *testing.T) {
+	// Syntetický text přesně podle Zipfa s exponentem 1: slovo na pořadí r má 10000/r výskytů
+	var words []string
+	for r := 1; r <= 500; r++ {
+		for i := 0; i < 10000/r; i++ {
+			words = append(words, fmt.Sprintf("w%d", r))
+		}
+	}
+	s := ComputeStatistics(strings.Join(words, " "), 10, nil)
+	t.Logf("Zipf: exponent %.3f (R² %.3f), MLE %.3f; Heaps: K %.2f, β %.3f (R² %.3f), %d+%d bodů",
+		s.Zipf.Exponent, s.Zipf.R2, s.Zipf.MLEExponent, s.Heaps.K, s.Heaps.Beta, s.Heaps.R2, len(s.Zipf.Points), len(s.Heaps.Points))
+	if math.Abs(s.Zipf.Exponent-1) > 0.05 || math.Abs(s.Zipf.MLEExponent-1) > 0.05 || s.Zipf.R2 < 0.99 {
+		t.Errorf("Zipf: exponent %.3f, MLE %.3f, R² %.3f, očekáván exponent 1", s.Zipf.Exponent, s.Zipf.MLEExponent, s.Zipf.R2)
+	}
+	if last := s.Zipf.Points[len(s.Zipf.Points)-1]; last.Rank != s.Unique {
+		t.Errorf("poslední bod Zipfa má pořadí %d, očekáváno %d", last.Rank, s.Unique)
+	}
+	if last := s.Heaps.Points[len(s.Heaps.Points)-1]; last != (VocabGrowth{s.Words, s.Unique}) {
+		t.Errorf("poslední bod Heapse %+v, očekáváno %d slov a %d unikátních", last, s.Words, s.Unique)
+	}
+
+	// Na reálném textu roste slovník sublineárně
+	real := ComputeStatistics(loadDataset(t), 0, nil)
+	t.Logf("Dataset: Zipf %.3f (MLE %.3f), Heaps β %.3f", real.Zipf.Exponent, real.Zipf.MLEExponent, real.Heaps.Beta)
+	if real.Heaps.Beta <= 0 || real.Heaps.Beta > 1 {
+		t.Errorf("Heaps β %.3f mimo (0, 1]", real.Heaps.Beta)
+	}
+}
+
+func TestLexikalniRozmanitost(t *testing.T) {
+	d := ComputeStatistics("a a b c c c", 0, nil).Diversity
+	// N = 6, V = 3, Σf² = 4+1+9 = 14, Σf(f-1) = 2+0+6 = 8
+	if math.Abs(d.TTR-0.5) > 1e-9 || d.Hapax != 1 || d.Dis != 1 {
+		t.Errorf("TTR %.3f, hapax %d, dis %d", d.TTR, d.Hapax, d.Dis)
+	}
+	if math.Abs(d.YuleK-1e4*8/36) > 1e-6 || math.Abs(d.SimpsonD-8.0/30) > 1e-9 {
+		t.Errorf("Yule K %.3f, Simpson D %.4f", d.YuleK, d.SimpsonD)
+	}
+	// Vzorek 42 je větší než text, HD-D se počítá z celého textu: každý typ se objeví
+	if math.Abs(d.HDD-3.0/6) > 1e-9 {
+		t.Errorf("HD-D %.4f, očekáváno 0.5", d.HDD)
+	}
+
+	// Text bez opakování má maximální rozmanitost
+	unique := ComputeStatistics("a b c d e f g h", 0, nil).Diversity
+	if unique.TTR != 1 || unique.YuleK != 0 || unique.SimpsonD != 0 || unique.MTLD != 8 {
+		t.Errorf("text bez opakování: %+v", unique)
+	}
+
+	ds := ComputeStatistics(loadDataset(t), 0, nil).Diversity
+	t.Logf("Dataset: TTR %.4f, root TTR %.3f, hapax %d, dis %d, Yule K %.2f, Simpson D %.5f, MTLD %.2f, HD-D %.4f",
+		ds.TTR, ds.RootTTR, ds.Hapax, ds.Dis, ds.YuleK, ds.SimpsonD, ds.MTLD, ds.HDD)
+}
+
+func TestZnakoveStatistiky(t *testing.T) {
+	c := ComputeStatistics("kočka a pes", 3, nil).Chars
+	if c.Chars != 11 || c.Categories["letter"] != 9 || c.Categories["space"] != 2 {
+		t.Errorf("znaky %d, kategorie %v", c.Chars, c.Categories)
+	}
+	if math.Abs(c.DiacriticsShare-1.0/9) > 1e-9 {
+		t.Errorf("podíl diakritiky %.3f, očekáváno 1/9", c.DiacriticsShare)
+	}
+	if math.Abs(c.AvgWordLength-3) > 1e-9 || histAt(c.WordLengths, 5) != 1 {
+		t.Errorf("délky slov %v, průměr %.2f", c.WordLengths, c.AvgWordLength)
+	}
+
+	// N-gramy se počítají s překryvem: "aaaa" obsahuje 3× "aa" a 2× "aaa"
+	c = ComputeStatistics("aaaa ab", 1, nil).Chars
+	if c.Bigrams[0] != (WordCount{"aa", 3}) || c.Trigrams[0] != (WordCount{"aaa", 2}) {
+		t.Errorf("bigramy %v, trigramy %v", c.Bigrams, c.Trigrams)
+	}
+
+	// Nejčastější bigram má stejnou četnost jako první merge ByteBPE
+	text := truncateText(loadDataset(t), 20000)
+	stats := ComputeStatistics(text, 20, nil)
+	first := ByteTokenizer{}.Train(text, 1).Merges[0]
+	if got := countOverlapping(text, first.A+first.B); got != stats.Chars.Bigrams[0].Count {
+		t.Errorf("první merge %q+%q má %d výskytů, nejčastější bigram %q %d",
+			first.A, first.B, got, stats.Chars.Bigrams[0].Word, stats.Chars.Bigrams[0].Count)
+	}
+	t.Logf("Bigramy: %s", formatCounts(stats.Chars.Bigrams))
+	t.Logf("Trigramy: %s", formatCounts(stats.Chars.Trigrams))
+}
+
+// countOverlapping spočítá výskyty sub v s včetně překrývajících se.
+func countOverlapping(s, sub string) int {
+	n := 0
+	for i := 0; ; i++ {
+		j := strings.Index(s[i:], sub)
+		if j < 0 {
+			return n
+		}
+		n++
+		i += j
+	}
+}
+
+func TestDeleniNaVety(t *testing.T) {
+	text := `Přípravek obsahuje např. paracetamol, tj. léčivou látku. Užívejte ho od 5. května ` +
+		`do 10. června. Dr. Smith said: "It works." Then he left! J. Novák souhlasil… Opravdu? ` +
+		`Ano.`
+	want := []string{
+		`Přípravek obsahuje např. paracetamol, tj. léčivou látku.`,
+		`Užívejte ho od 5. května do 10. června.`,
+		`Dr. Smith said: "It works."`,
+		`Then he left!`,
+		`J. Novák souhlasil…`,
+		`Opravdu?`,
+		`Ano.`,
+	}
+	got := NewSentenceSplitter().Split(text)
+	if len(got) != len(want) {
+		t.Fatalf("%d vět, očekáváno %d: %q", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("věta %d: %q, očekáváno %q", i, got[i], want[i])
+		}
+	}
+
+	// České věty končící slovem, které je v angličtině zkratkou
+	for _, tt := range []struct {
+		text string
+		want int
+	}{
+		{"Nevím co. Pak odešel.", 2},
+		{"No. Ano, přijdu.", 2},
+		// Za zkratkou věta končí před velkým písmenem, za titulem ne
+		{"Trvalo to 5 min. Pak odešel.", 2},
+		{"Koupil jablka, hrušky atd. Pak šel domů.", 2},
+		{"Přišel prof. Novák a sv. Václav.", 1},
+	} {
+		if got := NewSentenceSplitter().Split(tt.text); len(got) != tt.want {
+			t.Errorf("%q: %q, očekáváno %d vět", tt.text, got, tt.want)
+		}
+	}
+
+	st := ComputeSentenceStats(text, NewSentenceSplitter())
+	if st.Count != 7 || st.MinLength != 1 || st.MaxLength != 8 {
+		t.Errorf("statistiky vět %+v", st)
+	}
+
+	// Neznámou zkratku "ul." před velkým písmenem se dělič naučí z textu
+	learnText := strings.Repeat("Bydlím v ul. Dlouhá. Sídlo je v ul. Krátká. ", 3)
+	sp := &SentenceSplitter{Abbreviations: map[string]bool{}}
+	if n := len(sp.Split(learnText)); n != 12 {
+		t.Errorf("bez zkratek %d vět, očekáváno 12", n)
+	}
+	learned := sp.LearnAbbreviations(learnText, 2)
+	if len(learned) != 1 || learned[0] != "ul" {
+		t.Errorf("naučené zkratky %q, očekáváno [ul]", learned)
+	}
+	if n := len(sp.Split(learnText)); n != 6 {
+		t.Errorf("se zkratkou %d vět, očekáváno 6", n)
+	}
+}
+
+func TestKlicovaSlovaTFIDF(t *testing.T) {
+	jsonl := `{"id": 1, "text": "Léčivý přípravek obsahuje paracetamol. Přípravek užívejte s vodou."}
+{"name": "b", "text": "Přípravek je tableta a tableta se polyká."}
+
+{"text": "Kočka a pes."}
+`
+	docs, err := ReadJSONLDocuments(strings.NewReader(jsonl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 || docs[0].Name != "1" || docs[1].Name != "b" || docs[2].Name != "4" {
+		t.Fatalf("dokumenty %+v", docs)
+	}
+
+	c := NewCollection(docs, stopwords.Default())
+	if c.DF["přípravek"] != 2 || c.DF["a"] != 0 {
+		t.Errorf("df přípravek %d, a %d", c.DF["přípravek"], c.DF["a"])
+	}
+
+	// "přípravek" je ve dvou ze tří dokumentů, "tableta" jen v jednom a dvakrát
+	kws := c.Keywords(1, Weighting{}, 1)
+	if len(kws) != 1 || kws[0].Term != "tableta" || kws[0].TF != 2 {
+		t.Errorf("klíčová slova dokumentu b: %+v", kws)
+	}
+
+	// Dokument b bez stop slov: tableta (tf 2, df 1), přípravek (tf 1, df 2),
+	// polyká (tf 1, df 1); N = 3, max tf = 2
+	for _, tt := range []struct {
+		w    Weighting
+		term string
+		want float64
+	}{
+		{Weighting{RawTF, StandardIDF}, "tableta", 2 * math.Log(3)},
+		{Weighting{LogTF, StandardIDF}, "tableta", (1 + math.Log(2)) * math.Log(3)},
+		{Weighting{AugmentedTF, StandardIDF}, "přípravek", 0.75 * math.Log(1.5)},
+		{Weighting{BooleanTF, StandardIDF}, "tableta", math.Log(3)},
+		{Weighting{RawTF, SmoothIDF}, "přípravek", math.Log(2.5)},
+		{Weighting{RawTF, ProbIDF}, "tableta", 2 * math.Log(2)},
+		{Weighting{RawTF, ProbIDF}, "přípravek", 0}, // ln(1/2) < 0 se ořízne na 0
+	} {
+		got := math.NaN()
+		for _, kw := range c.Keywords(1, tt.w, -1) {
+			if kw.Term == tt.term {
+				got = kw.Score
+			}
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("TF %s, IDF %s, %s: %.6f, očekáváno %.6f", tt.w.TF, tt.w.IDF, tt.term, got, tt.want)
+		}
+		t.Logf("TF %-9s IDF %-8s %-10s %.4f", tt.w.TF, tt.w.IDF, tt.term, got)
+	}
+	// Term ve všech dokumentech: pravděpodobnostní IDF je 0, vyhlazené kladné
+	if got := (Weighting{IDF: ProbIDF}).idf(3, 3); got != 0 {
+		t.Errorf("prob IDF pro df = N je %g, očekáváno 0", got)
+	}
+	if got := (Weighting{IDF: SmoothIDF}).idf(3, 3); math.Abs(got-math.Log(2)) > 1e-12 {
+		t.Errorf("smooth IDF pro df = N je %g, očekáváno ln 2", got)
+	}
+
+	// Skryté soubory v adresáři (.DS_Store) nejsou dokumenty
+	dir := t.TempDir()
+	for name, text := range map[string]string{"a.txt": "kočka", ".DS_Store": "\x00\x01"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if docs, err := LoadDocuments(dir); err != nil || len(docs) != 1 || docs[0].Name != "a.txt" {
+		t.Errorf("dokumenty z adresáře %+v, chyba %v", docs, err)
+	}
+	if _, err := ReadJSONLDocuments(strings.NewReader("{nejson\n")); err == nil {
+		t.Error("očekávána chyba pro neplatný JSON")
+	}
+}
+
+func TestKeyness(t *testing.T) {
+	a := map[string]int{"přípravek": 50, "a": 100, "kočka": 1}
+	b := map[string]int{"přípravek": 5, "a": 100, "kočka": 40}
+
+	kws, err := Keyness(a, b, KeynessLL, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kws) != 2 || kws[0].Word != "přípravek" || kws[1].Word != "kočka" {
+		t.Fatalf("klíčová slova %+v", kws)
+	}
+	if kws[0].LL <= 0 || kws[1].LL >= 0 || kws[0].P != 0.0001 {
+		t.Errorf("G² přípravek %.2f (p %g), kočka %.2f", kws[0].LL, kws[0].P, kws[1].LL)
+	}
+	// Stejně časté slovo není významné
+	if all, _ := Keyness(a, b, KeynessLogRatio, 1); len(all) != 3 || all[1].Word != "a" {
+		t.Errorf("všechna slova podle log ratio %+v", all)
+	}
+	// Prázdný korpus je chyba, ne NaN a nekonečna
+	if _, err := Keyness(a, map[string]int{}, KeynessLL, 1); err == nil {
+		t.Error("očekávána chyba pro prázdný referenční korpus")
+	}
+	if _, err := Keyness(nil, b, KeynessLL, 1); err == nil {
+		t.Error("očekávána chyba pro prázdný cílový korpus")
+	}
+	for _, k := range kws {
+		t.Logf("%-10s %4d %4d G² %8.2f%-4s %%DIFF %8.2f log ratio %6.2f", k.Word, k.FreqA, k.FreqB, k.LL, k.significance(), k.PercentDiff, k.LogRatio)
+	}
+}
+
+func TestKonkordance(t *testing.T) {
+	text := "Léčivý přípravek je lék.\nPřípravky jiné. Tento léčivý  přípravek užívejte. Nepřípravek ne."
+
+	// Celá slova bez ohledu na velikost písmen a počet mezer ve frázi
+	lines, err := Concordance(text, "léčivý přípravek", ConcordanceOptions{Width: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0].Match != "Léčivý přípravek" || lines[1].Left != "né. Tento " || lines[1].Right != " užívejte." {
+		t.Errorf("konkordance fráze %+v", lines)
+	}
+
+	// Slovo uvnitř jiného slova ("Nepřípravek", "Přípravky") se nenajde
+	lines, _ = Concordance(text, "přípravek", ConcordanceOptions{Width: 8, Sort: SortByLeft})
+	if len(lines) != 2 || lines[0].Left != "Léčivý " {
+		t.Errorf("konkordance slova %+v", lines)
+	}
+
+	// Po odmítnuté shodě uvnitř slova se najde překrývající se výskyt
+	lines, _ = Concordance("ba a a", "a a", ConcordanceOptions{})
+	if len(lines) != 1 || lines[0].Offset != 3 {
+		t.Errorf("překrývající se výskyty %+v", lines)
+	}
+
+	// Rozlišení velikosti písmen
+	lines, _ = Concordance(text, "Léčivý", ConcordanceOptions{CaseSensitive: true})
+	if len(lines) != 1 || lines[0].Offset != 0 {
+		t.Errorf("konkordance s velikostí písmen %+v", lines)
+	}
+
+	// Regulární výraz, řazení podle pravého kontextu a limit
+	lines, _ = Concordance(text, `příprav\pL*`, ConcordanceOptions{Regex: true, Width: 8, Sort: SortByRight, Limit: 2})
+	if len(lines) != 2 || lines[0].Match != "přípravek" || lines[1].Match != "Přípravky" || !strings.HasPrefix(lines[0].Right, " je") {
+		t.Errorf("konkordance regulárního výrazu %+v", lines)
+	}
+
+	var sb strings.Builder
+	WriteConcordance(&sb, lines, 8)
+	for _, line := range strings.Split(strings.TrimRight(sb.String(), "\n"), "\n") {
+		t.Log(line)
+	}
+
+	if _, err := Concordance(text, "(", ConcordanceOptions{Regex: true}); err == nil {
+		t.Error("očekávána chyba pro neplatný regulární výraz")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// statLine odpovídá řádku výpisu printStatistics, např. ` 1. "the" — 12`.
+var statLine = regexp.MustCompile(`^\s*\d+\.\s+(".*")\s+—\s+(\d+)\s*$`)
+
+// ReadWordCounts načte tabulku četností slov. Podporuje řádky slovo<TAB>četnost
+// a řádky výpisu statistik (` 1. "slovo" — četnost`); ostatní řádky bez
+// tabulátoru (hlavičky výpisu, prázdné řádky, komentáře #) přeskočí. Opakované
+// slovo se sčítá.
+func ReadWordCounts(r io.Reader) (map[string]int, error) {
+	counts := make(map[string]int)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := sc.Text()
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var word, count string
+		if w, c, ok := strings.Cut(text, "\t"); ok {
+			word, count = w, c
+		} else if m := statLine.FindStringSubmatch(text); m != nil {
+			w, err := strconv.Unquote(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("četnosti slov: řádek %d: %w", line, err)
+			}
+			word, count = w, m[2]
+		} else {
+			continue
+		}
+
+		c, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil {
+			return nil, fmt.Errorf("četnosti slov: řádek %d: %w", line, err)
+		}
+		if c < 0 {
+			return nil, fmt.Errorf("četnosti slov: řádek %d: záporná četnost %d", line, c)
+		}
+		if word == "" || c == 0 {
+			continue
+		}
+		counts[word] += c
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// loadWordCounts načte a sečte tabulky četností z více souborů.
+func loadWordCounts(paths ...string) (map[string]int, error) {
+	total := make(map[string]int)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		counts, err := ReadWordCounts(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		addCounts(total, counts)
+	}
+	return total, nil
+}
+
+// addCounts přičte četnosti ze src do dst.
+func addCounts(dst, src map[string]int) {
+	for w, c := range src {
+		dst[w] += c
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+)
+
+// pointStep je poměr sousedních vzorků řad bodů; body jsou rozložené
+// logaritmicky, aby graf v log-log měřítku byl rovnoměrný a výstup malý.
+const pointStep = 1.05
+
+// ZipfFit je proložení Zipfova zákona f(r) = C · r^(-s) četnostmi slov.
+type ZipfFit struct {
+	// Exponent a Intercept jsou z metody nejmenších čtverců v log-log měřítku
+	// (ln f = Intercept - Exponent · ln r), R2 je koeficient determinace.
+	Exponent  float64 `json:"exponent"`
+	Intercept float64 `json:"intercept"`
+	R2        float64 `json:"r2"`
+	// MLEExponent je maximálně věrohodný exponent diskrétního Zipfova rozdělení
+	// na pořadích 1..Unique, LogLikelihood jeho log-věrohodnost.
+	MLEExponent   float64 `json:"mle_exponent"`
+	LogLikelihood float64 `json:"log_likelihood"`
+	// Points jsou logaritmicky vzorkované body (pořadí, četnost) pro graf.
+	Points []RankCount `json:"points"`
+}
+
+// RankCount je četnost slova na daném pořadí.
+type RankCount struct {
+	Rank  int `json:"rank"`
+	Count int `json:"count"`
+}
+
+// HeapsFit je proložení Heapsova zákona V(n) = K · n^β, kde V je počet
+// různých slov mezi prvními n slovy textu.
+type HeapsFit struct {
+	K      float64       `json:"k"`
+	Beta   float64       `json:"beta"`
+	R2     float64       `json:"r2"`
+	Points []VocabGrowth `json:"points"`
+}
+
+// VocabGrowth je velikost slovníku po Tokens slovech textu.
+type VocabGrowth struct {
+	Tokens int `json:"tokens"`
+	Vocab  int `json:"vocab"`
+}
+
+// fitZipf proloží Zipfův zákon četnostmi seřazenými sestupně.
+func fitZipf(sorted []WordCount) ZipfFit {
+	var fit ZipfFit
+	if len(sorted) < 2 {
+		return fit
+	}
+
+	xs := make([]float64, len(sorted))
+	ys := make([]float64, len(sorted))
+	for i, p := range sorted {
+		xs[i] = math.Log(float64(i + 1))
+		ys[i] = math.Log(float64(p.Count))
+	}
+	slope, intercept, r2 := linearFit(xs, ys)
+	fit.Exponent, fit.Intercept, fit.R2 = -slope, intercept, r2
+	fit.MLEExponent, fit.LogLikelihood = zipfMLE(sorted)
+
+	for r := 1; r <= len(sorted); r = nextSample(r) {
+		fit.Points = append(fit.Points, RankCount{r, sorted[r-1].Count})
+	}
+	if last := len(sorted); fit.Points[len(fit.Points)-1].Rank != last {
+		fit.Points = append(fit.Points, RankCount{last, sorted[last-1].Count})
+	}
+	return fit
+}
+
+// zipfMLE najde bisekcí exponent s, při kterém je střední ln r modelu
+// P(r) ∝ r^(-s) rovna střední ln r v datech (podmínka maxima věrohodnosti).
+func zipfMLE(sorted []WordCount) (s, logLik float64) {
+	var total, sumLog float64
+	for i, p := range sorted {
+		total += float64(p.Count)
+		sumLog += float64(p.Count) * math.Log(float64(i+1))
+	}
+	dataMean := sumLog / total
+	n := len(sorted)
+
+	modelMean := func(s float64) (mean, logZ float64) {
+		var z, zl float64
+		for r := 1; r <= n; r++ {
+			w := math.Pow(float64(r), -s)
+			z += w
+			zl += w * math.Log(float64(r))
+		}
+		return zl / z, math.Log(z)
+	}
+
+	lo, hi := 0.0, 10.0
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if m, _ := modelMean(mid); m > dataMean {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	s = (lo + hi) / 2
+	_, logZ := modelMean(s)
+	return s, -s*sumLog - total*logZ
+}
+
+// fitHeaps proloží Heapsův zákon body růstu slovníku.
+func fitHeaps(points []VocabGrowth) HeapsFit {
+	fit := HeapsFit{Points: points}
+	if len(points) < 2 {
+		return fit
+	}
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	for i, p := range points {
+		xs[i] = math.Log(float64(p.Tokens))
+		ys[i] = math.Log(float64(p.Vocab))
+	}
+	slope, intercept, r2 := linearFit(xs, ys)
+	fit.Beta, fit.K, fit.R2 = slope, math.Exp(intercept), r2
+	return fit
+}
+
+// vocabGrowth projde slova textu a logaritmicky vzorkuje velikost slovníku;
+// poslední bod odpovídá celému textu.
+func vocabGrowth(words []string) []VocabGrowth {
+	var points []VocabGrowth
+	seen := make(map[string]struct{})
+	next := 1
+	for i, w := range words {
+		seen[w] = struct{}{}
+		if n := i + 1; n == next || n == len(words) {
+			points = append(points, VocabGrowth{Tokens: n, Vocab: len(seen)})
+			next = nextSample(n)
+		}
+	}
+	return points
+}
+
+func nextSample(n int) int {
+	return max(n+1, int(float64(n)*pointStep))
+}
+
+// linearFit vrátí přímku y = slope·x + intercept metodou nejmenších čtverců
+// a koeficient determinace R².
+func linearFit(xs, ys []float64) (slope, intercept, r2 float64) {
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return 0, sy / n, 0
+	}
+	slope = (n*sxy - sx*sy) / den
+	intercept = (sy - slope*sx) / n
+
+	mean := sy / n
+	var ssRes, ssTot float64
+	for i := range xs {
+		d := ys[i] - (slope*xs[i] + intercept)
+		ssRes += d * d
+		ssTot += (ys[i] - mean) * (ys[i] - mean)
+	}
+	if ssTot == 0 {
+		return slope, intercept, 1
+	}
+	return slope, intercept, 1 - ssRes/ssTot
+}
+
+// WriteZipfCSV zapíše body Zipfova grafu se sloupci rank, count.
+func (s CorpusStats) WriteZipfCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"rank", "count"})
+	for _, p := range s.Zipf.Points {
+		cw.Write([]string{strconv.Itoa(p.Rank), strconv.Itoa(p.Count)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteHeapsCSV zapíše body Heapsova grafu se sloupci tokens, vocab.
+func (s CorpusStats) WriteHeapsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"tokens", "vocab"})
+	for _, p := range s.Heaps.Points {
+		cw.Write([]string{strconv.Itoa(p.Tokens), strconv.Itoa(p.Vocab)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
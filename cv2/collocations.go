@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ajrac/MATD/stopwords"
+)
+
+// Measure je míra asociace slov v kolokaci.
+type Measure int
+
+const (
+	PMI Measure = iota
+	LogLikelihood
+	TScore
+	ChiSquare
+	Dice
+)
+
+var measures = []Measure{PMI, LogLikelihood, TScore, ChiSquare, Dice}
+
+func (m Measure) String() string {
+	switch m {
+	case PMI:
+		return "PMI"
+	case LogLikelihood:
+		return "log-likelihood"
+	case TScore:
+		return "t-score"
+	case ChiSquare:
+		return "chí-kvadrát"
+	case Dice:
+		return "Dice"
+	}
+	return fmt.Sprintf("Measure(%d)", int(m))
+}
+
+// Collocation je n-gram slov s četností a skóre míry asociace.
+type Collocation struct {
+	Words []string
+	Count int
+	Score float64
+}
+
+func (c Collocation) String() string {
+	return strings.Join(c.Words, " ")
+}
+
+// findCollocations seřadí n-gramy (n = 2 nebo 3) s četností aspoň minFreq
+// sestupně podle míry asociace. Míry se počítají z kontingenční tabulky 2×2
+// dvojice (prefix, poslední slovo), u trigramů je prefixem bigram prvních
+// dvou slov. Se seznamem stop slov se vynechají n-gramy, které stop slovem
+// začínají nebo končí.
+func findCollocations(fields []string, n int, m Measure, minFreq int, stop stopwords.List) []Collocation {
+	ngrams := make(map[string]int)
+	prefixes := make(map[string]int)
+	lasts := make(map[string]int)
+	total := 0
+	for i := 0; i+n <= len(fields); i++ {
+		ngrams[strings.Join(fields[i:i+n], " ")]++
+		prefixes[strings.Join(fields[i:i+n-1], " ")]++
+		lasts[fields[i+n-1]]++
+		total++
+	}
+
+	var out []Collocation
+	for g, o11 := range ngrams {
+		if o11 < minFreq {
+			continue
+		}
+		words := strings.Fields(g)
+		if stop != nil && (stop.Contains(words[0]) || stop.Contains(words[n-1])) {
+			continue
+		}
+		prefix := strings.Join(words[:n-1], " ")
+		score := association(m, o11, prefixes[prefix], lasts[words[n-1]], total)
+		out = append(out, Collocation{Words: words, Count: o11, Score: score})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
+
+// association spočítá míru asociace z četnosti dvojice o11, četností jejích
+// částí c1, c2 a počtu všech dvojic n.
+func association(m Measure, o11, c1, c2, n int) float64 {
+	O11 := float64(o11)
+	O12 := float64(c1 - o11)
+	O21 := float64(c2 - o11)
+	O22 := float64(n - c1 - c2 + o11)
+	N := float64(n)
+	R1, R2 := O11+O12, O21+O22
+	C1, C2 := O11+O21, O12+O22
+	E11 := R1 * C1 / N
+
+	switch m {
+	case PMI:
+		return math.Log2(O11 / E11)
+	case TScore:
+		return (O11 - E11) / math.Sqrt(O11)
+	case ChiSquare:
+		den := R1 * R2 * C1 * C2
+		if den == 0 {
+			return 0
+		}
+		d := O11*O22 - O12*O21
+		return N * d * d / den
+	case LogLikelihood:
+		obs := [4]float64{O11, O12, O21, O22}
+		exp := [4]float64{E11, R1 * C2 / N, R2 * C1 / N, R2 * C2 / N}
+		var llr float64
+		for i := range obs {
+			if obs[i] > 0 {
+				llr += obs[i] * math.Log(obs[i]/exp[i])
+			}
+		}
+		return 2 * llr
+	case Dice:
+		return 2 * O11 / (R1 + C1)
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ajrac/MATD/stopwords"
+)
+
+func TestMiryAsociace(t *testing.T) {
+	// Kontingenční tabulka o11 = 2, c1 = 4, c2 = 3, n = 10:
+	//   O = [2 2; 1 5], R = (4, 6), C = (3, 7), E = [1,2 2,8; 1,8 4,2]
+	tests := []struct {
+		m    Measure
+		want float64
+	}{
+		{PMI, 0.736966},           // log2(2 / 1,2)
+		{TScore, 0.565685},        // (2 - 1,2) / √2
+		{ChiSquare, 1.269841},     // 10 · (2·5 - 2·1)² / (4·6·3·7)
+		{LogLikelihood, 1.265374}, // 2 · Σ O · ln(O / E)
+		{Dice, 0.571429},          // 2·2 / (4 + 3)
+	}
+	for _, tt := range tests {
+		got := association(tt.m, 2, 4, 3, 10)
+		if math.Abs(got-tt.want) > 1e-5 {
+			t.Errorf("%s = %.6f, chci %.6f", tt.m, got, tt.want)
+		}
+		t.Logf("%-15s %.6f", tt.m, got)
+	}
+}
+
+func TestFiltrKolokaci(t *testing.T) {
+	fields := strings.Fields("nový zákon o obcích nový zákon a nový zákon o krajích rychlý vlak")
+	stop := stopwords.List{"o": true, "a": true}
+
+	tests := []struct {
+		name    string
+		minFreq int
+		stop    stopwords.List
+		want    []string
+	}{
+		{"četnost ≥ 2", 2, nil, []string{"nový zákon", "zákon o"}},
+		{"četnost ≥ 2 bez stop slov", 2, stop, []string{"nový zákon"}},
+		{"četnost ≥ 4", 4, nil, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range findCollocations(fields, 2, Dice, tt.minFreq, tt.stop) {
+			got = append(got, c.String())
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: %q, chci %q", tt.name, got, tt.want)
+		}
+		t.Logf("%-28s %q", tt.name, got)
+	}
+}
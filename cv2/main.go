@@ -1,15 +1,24 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+
+	"github.com/ajrac/MATD/stopwords"
 )
 
 func main() {
+	// Minimální četnost n-gramu pro kolokace; u vzácných n-gramů PMI i Dice
+	// přeceňují náhodné shody.
+	minFreq := flag.Int("min-freq", 3, "minimální četnost n-gramu pro kolokace")
+	flag.Parse()
+
 	path := "C:\\Users\\ajrac\\Downloads\\cs (1).txt\\cs (1).txt"
-	if len(os.Args) > 1 {
-		path = os.Args[1]
+	if flag.NArg() > 0 {
+		path = flag.Arg(0)
 	}
 	fmt.Println("Using path:", path)
 
@@ -29,6 +38,32 @@ func main() {
 	fmt.Println("Best bigram:", best_bi)
 	fmt.Println("Best trigram:", best_tri)
 
+	// Bez stop slov; volitelný druhý argument je vlastní seznam stop slov
+	stop := stopwords.Default()
+	if flag.NArg() > 1 {
+		extra, err := stopwords.Load(flag.Arg(1))
+		if err != nil {
+			fmt.Println("Error reading stopwords:", err)
+			return
+		}
+		stop.Add(extra)
+	}
+	for n := 1; n <= 3; n++ {
+		fmt.Printf("Best %d-grams bez stop slov:\n", n)
+		ranked := rankNgrams(fields, n, stop)
+		for i := 0; i < len(ranked) && i < 10; i++ {
+			fmt.Printf("%2d. %q — %d\n", i+1, ranked[i].ngram, ranked[i].count)
+		}
+	}
+	for n := 2; n <= 3; n++ {
+		for _, m := range measures {
+			fmt.Printf("Kolokace (n=%d, %s, četnost ≥ %d):\n", n, m, *minFreq)
+			colls := findCollocations(fields, n, m, *minFreq, stop)
+			for i := 0; i < len(colls) && i < 10; i++ {
+				fmt.Printf("%2d. %q — %.3f (%d×)\n", i+1, colls[i].String(), colls[i].Score, colls[i].Count)
+			}
+		}
+	}
 }
 
 func clean(text string) string {
@@ -111,3 +146,33 @@ func createTrigram(fields []string) (map[string]map[string]float64, string) {
 	}
 	return trigramFreq, bestNgram
 }
+
+type ngramCount struct {
+	ngram string
+	count int
+}
+
+// rankNgrams spočítá n-gramy slov a seřadí je sestupně podle četnosti, při
+// shodě abecedně. Se seznamem stop slov vynechá n-gramy, které stop slovem
+// začínají nebo končí ("zákon o obcích" zůstane, "o obcích" ne).
+func rankNgrams(fields []string, n int, stop stopwords.List) []ngramCount {
+	freq := make(map[string]int)
+	for i := 0; i+n <= len(fields); i++ {
+		if stop != nil && (stop.Contains(fields[i]) || stop.Contains(fields[i+n-1])) {
+			continue
+		}
+		freq[strings.Join(fields[i:i+n], " ")]++
+	}
+
+	ranked := make([]ngramCount, 0, len(freq))
+	for g, c := range freq {
+		ranked = append(ranked, ngramCount{g, c})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].count == ranked[j].count {
+			return ranked[i].ngram < ranked[j].ngram
+		}
+		return ranked[i].count > ranked[j].count
+	})
+	return ranked
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ajrac/MATD/stopwords"
+)
+
+func TestNgramyBezStopSlov(t *testing.T) {
+	fields := strings.Fields("zákon o obcích a zákon o krajích a zákon o obcích")
+	stop := stopwords.List{"o": true, "a": true}
+
+	tests := []struct {
+		n    int
+		stop stopwords.List
+		want string
+	}{
+		{1, nil, "o 3|zákon 3|a 2|obcích 2|krajích 1"},
+		{1, stop, "zákon 3|obcích 2|krajích 1"},
+		// Každý bigram začíná nebo končí stop slovem
+		{2, stop, ""},
+		// Stop slovo uprostřed trigramu nevadí
+		{3, stop, "zákon o obcích 2|krajích a zákon 1|obcích a zákon 1|zákon o krajích 1"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, g := range rankNgrams(fields, tt.n, tt.stop) {
+			got = append(got, fmt.Sprintf("%s %d", g.ngram, g.count))
+		}
+		if s := strings.Join(got, "|"); s != tt.want {
+			t.Errorf("n=%d, stop %v: %s, chci %s", tt.n, tt.stop != nil, s, tt.want)
+		}
+		t.Logf("n=%d %q", tt.n, got)
+	}
+}
@@ -0,0 +1,81 @@
+// Package stopwords obsahuje vestavěné seznamy českých a anglických
+// funkčních slov (stop slov) a načítání uživatelských seznamů.
+package stopwords
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	//go:embed cs.txt
+	czechList string
+	//go:embed en.txt
+	englishList string
+)
+
+// List je množina stop slov malými písmeny.
+type List map[string]bool
+
+// Czech vrátí vestavěný seznam českých stop slov.
+func Czech() List {
+	l, _ := Read(strings.NewReader(czechList))
+	return l
+}
+
+// English vrátí vestavěný seznam anglických stop slov.
+func English() List {
+	l, _ := Read(strings.NewReader(englishList))
+	return l
+}
+
+// Default vrátí sjednocení vestavěných českých a anglických seznamů.
+func Default() List {
+	return Czech().Add(English())
+}
+
+// Read načte seznam stop slov: jedno slovo na řádek, prázdné řádky a řádky
+// začínající # se přeskočí. Slova se převedou na malá písmena.
+func Read(r io.Reader) (List, error) {
+	l := make(List)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		w := strings.TrimSpace(sc.Text())
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		l[strings.ToLower(w)] = true
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Load načte seznam stop slov ze souboru na dané cestě.
+func Load(path string) (List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Add přidá do seznamu slova ostatních seznamů a vrátí ho.
+func (l List) Add(others ...List) List {
+	for _, o := range others {
+		for w := range o {
+			l[w] = true
+		}
+	}
+	return l
+}
+
+// Contains hlásí, zda je slovo (bez ohledu na velikost písmen) stop slovem.
+func (l List) Contains(w string) bool {
+	return l[strings.ToLower(w)]
+}